@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/search"
+
+	"github.com/spf13/cobra"
+)
+
+// indexPath returns where the search index of repoRoot is stored, next to the config file
+func indexPath(cfgManager *config.Manager, repoRoot string) string {
+	sum := sha1.Sum([]byte(repoRoot))
+	name := filepath.Base(repoRoot) + "-" + hex.EncodeToString(sum[:])[:12] + ".json"
+	return filepath.Join(filepath.Dir(cfgManager.GetPath()), "index", name)
+}
+
+// refreshIndex adds the commits made since the index was last refreshed. When the
+// previous head is not an ancestor of HEAD, after a rebase or an amend, the index is
+// rebuilt from scratch so the rewritten commits are dropped.
+func refreshIndex(vcs *git.GitVCS, repoRoot string, idx *search.Index) (int, error) {
+	head, err := vcs.GetLastCommitHash(repoRoot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	head = strings.TrimSpace(head)
+	if head == idx.Head {
+		return 0, nil
+	}
+
+	revRange := "HEAD"
+	if idx.Head != "" {
+		ok, err := vcs.IsAncestor(repoRoot, idx.Head, head)
+		if err != nil {
+			debug.Printf("Failed to find the indexed head %s: %v", idx.Head, err)
+		}
+		if ok {
+			revRange = idx.Head + "..HEAD"
+		} else {
+			debug.Printf("%s is not an ancestor of HEAD, rebuilding index", idx.Head)
+			idx.Head, idx.Documents = "", nil
+		}
+	}
+	commits, err := vcs.GetCommitsWithFiles(repoRoot, revRange)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read commits: %w", err)
+	}
+
+	docs := make([]search.Document, 0, len(commits))
+	for _, c := range commits {
+		var files []string
+		if c.Extra != "" {
+			files = strings.Split(c.Extra, "\n")
+		}
+		docs = append(docs, search.Document{
+			Hash:    c.Hash,
+			Author:  c.Author,
+			Date:    c.Date,
+			Subject: c.Subject,
+			Body:    c.Body,
+			Files:   files,
+		})
+	}
+	added := idx.Add(docs)
+	idx.Head = head
+	return added, nil
+}
+
+// NewAskCmd creates a new ask command
+func NewAskCmd() *cobra.Command {
+	var (
+		limit   int
+		rebuild bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ask [question]",
+		Short: "Ask a question about the repository history",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			question := strings.Join(args, " ")
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+
+			vcs := &git.GitVCS{}
+			repoRoot, err := vcs.GetRepoRoot(cwd)
+			if err != nil {
+				return fmt.Errorf("failed to find repository root: %w", err)
+			}
+			debug.Printf("Using repository root: %s", repoRoot)
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			// Load and refresh the local index
+			path := indexPath(cfgManager, repoRoot)
+			idx := &search.Index{}
+			if !rebuild {
+				idx, err = search.Load(path)
+				if err != nil {
+					return err
+				}
+			}
+			previous := idx.Head
+			added, err := refreshIndex(vcs, repoRoot, idx)
+			if err != nil {
+				return err
+			}
+			if idx.Head != previous {
+				if err := idx.Save(path); err != nil {
+					return err
+				}
+			}
+			debug.Printf("Indexed %d new commits, %d total, stored at %s", added, len(idx.Documents), path)
+
+			results := idx.Search(question, limit)
+			if len(results) == 0 {
+				fmt.Println("No commits match your question")
+				return nil
+			}
+
+			var matches strings.Builder
+			for _, r := range results {
+				debug.Printf("Match %s score %.3f: %s", r.Hash, r.Score, r.Subject)
+				matches.WriteString(r.String())
+				matches.WriteString("\n")
+			}
+
+			lang, err := outputLanguage(cfgManager)
+			if err != nil {
+				return err
+			}
+
+			prompt, err := renderPrompt(cfgManager.GetNamedPrompt("ask"), promptVars{
+				"question":    question,
+				"output.lang": lang,
+				"placeholder": matches.String(),
+			})
+			if err != nil {
+				return err
+			}
+
+			// Get client config
+			clientConfig, err := cfgManager.GetClientConfig()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("🤖 Reading %d relevant commits...\n", len(results))
+			resp, err := client.New(clientConfig).Chat(context.Background(), prompt, nil)
+			if err != nil {
+				return fmt.Errorf("failed to answer question: %w", err)
+			}
+
+			fmt.Printf("\n%s\n", strings.TrimSpace(resp.Content))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum number of commits to send as context")
+	cmd.Flags().BoolVar(&rebuild, "rebuild", false, "Rebuild the local index from scratch")
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/search"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAskCmd(t *testing.T) {
+	cmd := NewAskCmd()
+	require.NotNil(t, cmd)
+
+	assert.NotNil(t, cmd.Flags().Lookup("limit"))
+	assert.NotNil(t, cmd.Flags().Lookup("rebuild"))
+	assert.Error(t, cmd.Args(cmd, []string{}))
+}
+
+func TestRefreshIndex(t *testing.T) {
+	_, repoPath, cleanup := setupTestRepo(t, git.Git)
+	defer cleanup()
+	vcs := &git.GitVCS{}
+
+	commitFile := func(name, msg string) {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(msg), 0644))
+		require.NoError(t, testutils.RunGitCommand(t, repoPath, "add", name))
+		require.NoError(t, testutils.RunGitCommand(t, repoPath, "commit", "-m", msg))
+	}
+
+	commitFile("a.go", "feat: add retry logic")
+	idx := &search.Index{}
+	added, err := refreshIndex(vcs, repoPath, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, []string{"a.go"}, idx.Documents[0].Files)
+
+	// Nothing new since the last refresh
+	added, err = refreshIndex(vcs, repoPath, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	commitFile("b.go", "fix: retry on timeout")
+	added, err = refreshIndex(vcs, repoPath, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, "fix: retry on timeout", idx.Documents[0].Subject)
+
+	// An unknown head triggers a full rebuild
+	idx.Head = "0000000000000000000000000000000000000000"
+	added, err = refreshIndex(vcs, repoPath, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Len(t, idx.Documents, 2)
+
+	// Commits rewritten by an amend are dropped
+	require.NoError(t, testutils.RunGitCommand(t, repoPath, "commit", "--amend", "-m", "fix: retry on every timeout"))
+	added, err = refreshIndex(vcs, repoPath, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	require.Len(t, idx.Documents, 2)
+	assert.Equal(t, "fix: retry on every timeout", idx.Documents[0].Subject)
+
+	// Commits removed by a reset are dropped
+	require.NoError(t, testutils.RunGitCommand(t, repoPath, "reset", "--hard", "HEAD~1"))
+	added, err = refreshIndex(vcs, repoPath, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	require.Len(t, idx.Documents, 1)
+	assert.Equal(t, "feat: add retry logic", idx.Documents[0].Subject)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/bench"
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// formatBenchSummaries renders the comparison table of the benchmarked targets
+func formatBenchSummaries(summaries []bench.Summary) string {
+	var b strings.Builder
+	duration := func(d time.Duration) string {
+		if d == 0 {
+			return "-"
+		}
+		return d.Round(time.Millisecond).String()
+	}
+
+	fmt.Fprintf(&b, "%-32s %8s %7s %9s %9s %9s %9s %9s\n",
+		"target", "requests", "failed", "p50", "p95", "ttft p50", "prompt", "output")
+	for _, s := range summaries {
+		prompt, output := "-", "-"
+		if s.UsageSamples > 0 {
+			prompt = fmt.Sprintf("%d", s.PromptTokens/s.UsageSamples)
+			output = fmt.Sprintf("%.0f", s.AvgCompletionTokens())
+		}
+		fmt.Fprintf(&b, "%-32s %8d %6.0f%% %9s %9s %9s %9s %9s\n",
+			s.Target, s.Requests, s.FailureRate()*100,
+			duration(s.P50), duration(s.P95), duration(s.FirstByteP50), prompt, output)
+	}
+	return b.String()
+}
+
+// NewBenchCmd creates a new bench command
+func NewBenchCmd() *cobra.Command {
+	var (
+		models      []string
+		runs        int
+		concurrency int
+		timeout     int
+		rich        bool
+		apiBase     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench [sample...]",
+		Short: "Benchmark the latency and token usage of providers",
+		Long: `Send the same sample diffs to several providers and compare their latency.
+
+Samples are diff files, or JSONL files with one {"id", "diff"} object per line.
+Without samples the staged changes of the current repository are used.
+
+The report shows the failure rate, p50 and p95 latency, the median time to first token
+and the average prompt and output tokens per request. Requests are streamed, so the time
+to first token is the time until the first chunk of the answer arrived. For providers
+that do not stream it is the time until the response headers arrived, close to the
+total latency.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			var samples []bench.Sample
+			if len(args) > 0 {
+				samples, err = bench.LoadSamples(args)
+				if err != nil {
+					return err
+				}
+			} else {
+				repoPath, err := os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get current directory: %w", err)
+				}
+				diff, err := (&git.GitVCS{}).GetStagedDiffFiltered(repoPath, cfgManager)
+				if err != nil {
+					return fmt.Errorf("failed to get staged diff: %w", err)
+				}
+				if strings.TrimSpace(diff) == "" {
+					return fmt.Errorf("no staged changes found, pass sample files to benchmark")
+				}
+				samples = []bench.Sample{{ID: "staged", Diff: diff}}
+			}
+
+			if len(models) == 0 {
+				active, _ := cfgManager.Get("provider")
+				provider, ok := active.(string)
+				if !ok || provider == "" {
+					return fmt.Errorf("provider not set, pass --model provider/model")
+				}
+				models = []string{provider}
+			}
+			var names []string
+			clients := make(map[string]*client.Client)
+			for _, spec := range models {
+				target, err := parseEvalTarget(cfgManager, spec, apiBase)
+				if err != nil {
+					return fmt.Errorf("invalid target %s: %w", spec, err)
+				}
+				if _, ok := clients[target.Name]; !ok {
+					names = append(names, target.Name)
+				}
+				clients[target.Name] = client.New(target.Config)
+			}
+
+			// Render the prompts up front, an invalid prompt fails every request
+			prompt := cfgManager.GetPrompt(nil, rich)
+			prompts := make(map[string]string, len(samples))
+			for _, sample := range samples {
+				prompts[sample.Diff], err = renderPrompt(prompt, commitPromptVars(cfgManager, nil, "", sample.Diff, ""))
+				if err != nil {
+					return err
+				}
+			}
+
+			send := func(ctx context.Context, target string, sample bench.Sample) (*types.CompletionResponse, error) {
+				ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+				defer cancel()
+				// Streamed so that the time to first token is measured on the first chunk
+				resp, err := clients[target].Stream(ctx, prompts[sample.Diff], nil, func(string) {})
+				if err != nil {
+					debug.Printf("Sample %s failed on %s: %v", sample.ID, target, err)
+				}
+				return resp, err
+			}
+			progress := func(done, total int) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "\rRequests: %d/%d", done, total)
+			}
+
+			results := bench.Run(context.Background(), names, samples, runs, concurrency, send, progress)
+			fmt.Fprintln(cmd.ErrOrStderr())
+
+			fmt.Fprint(cmd.OutOrStdout(), formatBenchSummaries(bench.Summarize(names, results)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&models, "model", "m", nil, "Provider or provider/model to benchmark, can be repeated (default: the active provider)")
+	cmd.Flags().IntVarP(&runs, "runs", "n", 3, "Number of times every sample is sent to every target")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "Maximum number of requests in flight, 1 runs sequentially")
+	cmd.Flags().IntVar(&timeout, "timeout", 60, "Timeout of each request in seconds")
+	cmd.Flags().BoolVarP(&rich, "rich", "r", false, "Benchmark the rich commit message prompt")
+	cmd.Flags().StringVar(&apiBase, "api-base", "", "Send every request to this API base, e.g. a local mock server")
+
+	return cmd
+}
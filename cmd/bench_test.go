@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchCmd(t *testing.T) {
+	server := newMockCompletionServer(t)
+	defer server.Close()
+
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: https://example.invalid/v1
+  model: gpt-4o
+`)
+	defer cleanup()
+
+	sample := filepath.Join(t.TempDir(), "change.diff")
+	require.NoError(t, os.WriteFile(sample, []byte("diff --git a/config.go b/config.go"), 0644))
+
+	// The persistent flags of the real root command, whose shorthands must not clash
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().BoolP("debug", "d", false, "")
+	root.PersistentFlags().StringP("config", "c", configPath, "")
+	root.PersistentFlags().StringArray("set", nil, "")
+	root.AddCommand(NewBenchCmd())
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetArgs([]string{"bench", sample,
+		"--model", "openai", "--model", "openai/broken",
+		"--runs", "2", "--concurrency", "2", "--api-base", server.URL})
+	require.NoError(t, root.Execute())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^openai/gpt-4o\s+2\s+0%\s+\S+\s+\S+\s+\S+\s+100\s+6$`, lines[1])
+	assert.Regexp(t, `^openai/broken\s+2\s+100%\s+-\s+-\s+-\s+-\s+-$`, lines[2])
+	assert.Contains(t, errOut.String(), "Requests: 4/4")
+
+	out.Reset()
+	root.SetArgs([]string{"bench", "--help"})
+	require.NoError(t, root.Execute())
+	assert.Contains(t, out.String(), "--concurrency")
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+// kebabCase lowercases text and joins its words with hyphens
+func kebabCase(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// parseBranchAnswer splits a "<type>: <summary>" answer from the LLM
+func parseBranchAnswer(answer string) (string, string) {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(answer), "\n", 2)[0])
+	line = strings.Trim(line, "`")
+	typ, summary, found := strings.Cut(line, ":")
+	if !found {
+		return "", line
+	}
+	return strings.ToLower(strings.TrimSpace(typ)), strings.TrimSpace(summary)
+}
+
+// findTicket returns the first ticket key in text matching pattern
+func findTicket(pattern, text string) (string, error) {
+	if pattern == "" {
+		return "", nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid branch.ticket_pattern: %w", err)
+	}
+	return re.FindString(text), nil
+}
+
+// buildBranchName assembles <prefix><ticket>-<kebab-summary>, truncated to the
+// configured maximum length on a word boundary and without trailing separators.
+// Types without a configured prefix, e.g. one made up by the model, get no prefix.
+func buildBranchName(cfg config.BranchConfig, typ, summary, ticket string) string {
+	prefix := cfg.Prefixes[typ]
+
+	name := prefix
+	if ticket != "" {
+		name += ticket + "-"
+	}
+	name += kebabCase(summary)
+
+	// Summaries may be written in any script, the length is counted in characters
+	if cfg.MaxLength > 0 && utf8.RuneCountInString(name) > cfg.MaxLength {
+		name = string([]rune(name)[:cfg.MaxLength])
+		if idx := strings.LastIndex(name, "-"); idx > len(prefix) {
+			name = name[:idx]
+		}
+	}
+	return strings.TrimRight(name, "-/")
+}
+
+// NewBranchCmd creates a new branch command
+func NewBranchCmd() *cobra.Command {
+	var (
+		fromStaged bool
+		ticket     string
+		create     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "branch [description]",
+		Short: "Suggest a branch name from a description or staged changes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			description := strings.TrimSpace(strings.Join(args, " "))
+			if description == "" && !fromStaged {
+				return fmt.Errorf("provide a description or use --from-staged")
+			}
+
+			repoPath, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+			debug.Printf("Using repository path: %s", repoPath)
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+			branchConfig := cfgManager.GetBranchConfig()
+
+			vcs := &git.GitVCS{}
+			work := description
+			if fromStaged {
+				diff, err := vcs.GetStagedDiffFiltered(repoPath, cfgManager)
+				if err != nil {
+					return fmt.Errorf("failed to get diff: %w", err)
+				}
+				if diff == "" {
+					return fmt.Errorf("no staged changes found")
+				}
+				if description != "" {
+					work = description + "\n\n" + diff
+				} else {
+					work = diff
+				}
+			}
+
+			if ticket == "" {
+				ticket, err = findTicket(branchConfig.TicketPattern, description)
+				if err != nil {
+					return err
+				}
+			}
+
+			types := make([]string, 0, len(branchConfig.Prefixes))
+			for typ := range branchConfig.Prefixes {
+				types = append(types, typ)
+			}
+			sort.Strings(types)
+
+			prompt, err := renderPrompt(cfgManager.GetNamedPrompt("branch"), promptVars{
+				"types":       strings.Join(types, ", "),
+				"placeholder": work,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Get client config
+			clientConfig, err := cfgManager.GetClientConfig()
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("🤖 Thinking of a good name...")
+			resp, err := client.New(clientConfig).Chat(context.Background(), prompt, nil)
+			if err != nil {
+				return fmt.Errorf("failed to suggest branch name: %w", err)
+			}
+
+			typ, summary := parseBranchAnswer(resp.Content)
+			debug.Printf("Branch type: %q, summary: %q, ticket: %q", typ, summary, ticket)
+			name := buildBranchName(branchConfig, typ, summary, ticket)
+			if name == "" {
+				return fmt.Errorf("failed to build a branch name from %q", resp.Content)
+			}
+
+			if err := vcs.CheckRefFormat(repoPath, name); err != nil {
+				return err
+			}
+
+			fmt.Printf("\nSuggested branch name:\n%s\n", formatCommitMessage(name))
+
+			if !create {
+				return nil
+			}
+			if err := vcs.CreateBranch(repoPath, name); err != nil {
+				return fmt.Errorf("failed to create branch: %w", err)
+			}
+			fmt.Printf("Switched to a new branch '%s'\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&fromStaged, "from-staged", false, "Derive the branch name from staged changes")
+	cmd.Flags().StringVarP(&ticket, "ticket", "t", "", "Ticket key to include, detected from the description by default")
+	cmd.Flags().BoolVar(&create, "create", false, "Create the branch and switch to it")
+
+	return cmd
+}
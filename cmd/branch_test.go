@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBranchCmd(t *testing.T) {
+	cmd := NewBranchCmd()
+	require.NotNil(t, cmd)
+
+	for _, name := range []string{"from-staged", "ticket", "create"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "flag %q not found", name)
+	}
+
+	cmd.SetArgs([]string{})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--from-staged")
+}
+
+func TestParseBranchAnswer(t *testing.T) {
+	typ, summary := parseBranchAnswer("feat: add oauth login\nextra")
+	assert.Equal(t, "feat", typ)
+	assert.Equal(t, "add oauth login", summary)
+
+	typ, summary = parseBranchAnswer("`Fix: handle empty config`")
+	assert.Equal(t, "fix", typ)
+	assert.Equal(t, "handle empty config", summary)
+
+	typ, summary = parseBranchAnswer("just words")
+	assert.Empty(t, typ)
+	assert.Equal(t, "just words", summary)
+}
+
+func TestFindTicket(t *testing.T) {
+	ticket, err := findTicket(config.DefaultTicketPattern, "PROJ-123 add login")
+	require.NoError(t, err)
+	assert.Equal(t, "PROJ-123", ticket)
+
+	ticket, err = findTicket(config.DefaultTicketPattern, "add login")
+	require.NoError(t, err)
+	assert.Empty(t, ticket)
+
+	_, err = findTicket("[", "x")
+	assert.Error(t, err)
+}
+
+func TestBuildBranchName(t *testing.T) {
+	cfg := config.BranchConfig{
+		Prefixes:  config.DefaultBranchPrefixes,
+		MaxLength: 30,
+	}
+
+	tests := []struct {
+		name    string
+		typ     string
+		summary string
+		ticket  string
+		want    string
+	}{
+		{"known type", "feat", "Add OAuth login!", "", "feature/add-oauth-login"},
+		{"with ticket", "fix", "handle empty config", "PROJ-1", "fix/PROJ-1-handle-empty-config"},
+		{"unknown type", "sec", "patch xss", "", "patch-xss"},
+		{"unknown type with ticket", "wip", "patch xss", "PROJ-1", "PROJ-1-patch-xss"},
+		{"no type", "", "patch xss", "", "patch-xss"},
+		{"truncated on word", "feat", "support generating very long branch names", "", "feature/support-generating"},
+		{"truncated on characters", "feat", "поддержка очень длинных имён веток", "", "feature/поддержка-очень"},
+		{"no trailing separator", "feat", "", "PROJ-1", "feature/PROJ-1"},
+		{"prefix only", "docs", "!!!", "", "docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildBranchName(cfg, tt.typ, tt.summary, tt.ticket)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, utf8.RuneCountInString(got), cfg.MaxLength)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+}
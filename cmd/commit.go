@@ -8,9 +8,11 @@ import (
 	"syscall"
 
 	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/commitmsg"
 	"github.com/belingud/go-gptcomet/internal/config"
 	"github.com/belingud/go-gptcomet/internal/debug"
 	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/history"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
@@ -114,6 +116,8 @@ func NewCommitCmd() *cobra.Command {
 		dryRun   bool
 		useSVN   bool
 		autoYes  bool
+		hint     string
+		profile  string
 	)
 
 	cmd := &cobra.Command{
@@ -161,8 +165,8 @@ func NewCommitCmd() *cobra.Command {
 				return fmt.Errorf("failed to get config path: %w", err)
 			}
 
-			// Create config manager
-			cfgManager, err := config.New(configPath)
+			// Create config manager, with the config of the repository
+			cfgManager, err := config.NewForRepo(configPath, repoPath)
 			if err != nil {
 				return fmt.Errorf("failed to create config manager: %w", err)
 			}
@@ -183,9 +187,42 @@ func NewCommitCmd() *cobra.Command {
 				return err
 			}
 
+			// Select the prompt profile and its model parameters
+			promptProfile, err := resolveProfile(cfgManager, vcs, repoPath, profile)
+			if err != nil {
+				return err
+			}
+			promptProfile.Apply(clientConfig)
+
 			// Create client
 			client := client.New(clientConfig)
 
+			// Record every generated message in the history
+			store := historyStore(cfgManager)
+			profileName := "brief"
+			if rich {
+				profileName = "rich"
+			}
+			if promptProfile != nil {
+				profileName = promptProfile.Name
+			}
+			var entry *history.Entry
+
+			// The scopes derived from the staged paths are enforced on the generated message
+			var scopes []string
+			if gitVCS, ok := vcs.(*git.GitVCS); ok {
+				var byScope map[string][]string
+				scopes, byScope, err = commitmsg.StagedScopes(cfgManager, gitVCS, repoPath)
+				if err != nil {
+					return err
+				}
+				if len(scopes) > 1 && cfgManager.GetScopeConfig().Multiple == config.ScopesSplit {
+					fmt.Print(formatSplitSuggestion(scopes, byScope))
+				}
+			}
+
+			commitTypes := cfgManager.GetCommitTypes()
+			outputStyle := cfgManager.GetOutputStyle()
 			reader := bufio.NewReader(os.Stdin)
 			var commitMsg string
 			for {
@@ -195,36 +232,71 @@ func NewCommitCmd() *cobra.Command {
 				fmt.Println("🤖 Hang tight, I'm cooking up something good!")
 
 				// Get prompt based on rich flag
-				prompt := cfgManager.GetPrompt(rich)
+				prompt := cfgManager.GetPrompt(promptProfile, rich)
 
 				if commitMsg == "" {
 					// Generate commit message
 					var err error
-					commitMsg, err = client.GenerateCommitMessage(diff, prompt)
+					commitMsg, err = client.GenerateCommitMessage(prompt, commitPromptVars(cfgManager, vcs, repoPath, diff, hint))
 					if err != nil {
 						return fmt.Errorf("failed to generate commit message: %w", err)
 					}
-				}
+					// If output.lang is not "en", prompt for translation
+					var lang string
+					langValue, ok := cfgManager.Get(LANGUAGE_KEY)
+					if !ok {
+						return fmt.Errorf("failed to get output.lang: configuration key not found")
+					}
+					lang, ok = langValue.(string)
+					if !ok {
+						return fmt.Errorf("output.lang is not a string: %v", langValue)
+					}
+					finish := commitmsg.Options{
+						Style:  outputStyle,
+						Types:  commitTypes,
+						Scopes: scopes,
+						Warn: func(err error) {
+							fmt.Printf("Warning: %v\n", err)
+						},
+					}
+					if lang != "en" {
+						translatePrompt := cfgManager.GetTranslationPrompt(promptProfile)
+						langName, err := outputLanguage(cfgManager)
+						if err != nil {
+							return err
+						}
+						finish.Translate = func(message string) (string, error) {
+							translated, err := client.TranslateMessage(translatePrompt, message, langName)
+							if err != nil {
+								return "", fmt.Errorf("failed to translate commit message: %w", err)
+							}
+							return translated, nil
+						}
+					}
 
-				// If output.lang is not "en", prompt for translation
-				var lang string
-				langValue, ok := cfgManager.Get(LANGUAGE_KEY)
-				if !ok {
-					return fmt.Errorf("failed to get output.lang: configuration key not found")
-				}
-				lang, ok = langValue.(string)
-				if !ok {
-					return fmt.Errorf("output.lang is not a string: %v", langValue)
-				}
-				if lang != "en" {
-					translatePrompt := cfgManager.GetTranslationPrompt()
-					commitMsg, err = client.TranslateMessage(translatePrompt, commitMsg, lang)
+					// Scopes and style are applied once to the generated message, edits are kept as written
+					commitMsg, err = commitmsg.Finish(commitMsg, finish)
 					if err != nil {
-						return fmt.Errorf("failed to translate commit message: %w", err)
+						return err
 					}
 				}
 				fmt.Printf("\nGenerated commit message:\n%s\n", formatCommitMessage(commitMsg))
 
+				if entry == nil {
+					entry = &history.Entry{
+						Repo:     historyRepo(vcs, repoPath),
+						DiffHash: history.HashDiff(diff),
+						Profile:  profileName,
+						Provider: clientConfig.Provider,
+						Model:    clientConfig.Model,
+						Message:  commitMsg,
+						Status:   history.StatusGenerated,
+					}
+					saveHistory(store, entry)
+				} else if commitMsg != entry.Message {
+					entry.Final = commitMsg
+				}
+
 				// If dry-run is set, exit here without committing
 				if dryRun {
 					return nil
@@ -253,7 +325,9 @@ func NewCommitCmd() *cobra.Command {
 					// Create commit
 					err = vcs.CreateCommit(repoPath, commitMsg)
 					if err != nil {
-						return fmt.Errorf("failed to create commit: %w", err)
+						entry.Status = history.StatusFailed
+						saveHistory(store, entry)
+						return fmt.Errorf("failed to create commit: %w, run `gptcomet history reuse %s` to retry with this message", err, entry.ID)
 					}
 
 					// Get commit hash
@@ -262,6 +336,13 @@ func NewCommitCmd() *cobra.Command {
 						return fmt.Errorf("failed to get commit hash: %w", err)
 					}
 
+					entry.Status = history.StatusAccepted
+					if entry.Final != "" {
+						entry.Status = history.StatusEdited
+					}
+					entry.CommitHash = strings.TrimSpace(commitHash)
+					saveHistory(store, entry)
+
 					// Get commit info
 					commitInfo, err := vcs.GetCommitInfo(repoPath, commitHash)
 					if err != nil {
@@ -271,9 +352,14 @@ func NewCommitCmd() *cobra.Command {
 					fmt.Printf("\nSuccessfully created commit:\n%s\n", commitInfo)
 					return nil
 				case "n", "no":
+					entry.Status = history.StatusRejected
+					saveHistory(store, entry)
 					fmt.Println("Operation cancelled")
 					return nil
 				case "r", "retry":
+					entry.Status = history.StatusRejected
+					saveHistory(store, entry)
+					entry = nil
 					commitMsg = ""
 					continue
 				case "e", "edit":
@@ -297,6 +383,8 @@ func NewCommitCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(&autoYes, "yes", "y", false, "Automatically commit without asking")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated commit message and exit without committing")
 	cmd.Flags().BoolVar(&useSVN, "svn", false, "Use SVN instead of Git")
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "Prompt profile from prompt.profiles, overriding the default profile of the repository")
+	cmd.Flags().StringVar(&hint, "hint", "", "Extra context for the commit message, available to prompts as {{ hint }}")
 
 	return cmd
 }
@@ -10,9 +10,12 @@ import (
 	"path/filepath"
 
 	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/history"
 	"github.com/belingud/go-gptcomet/internal/testutils"
 	"github.com/belingud/go-gptcomet/pkg/config"
+	"github.com/belingud/go-gptcomet/pkg/gptcomet"
 	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -266,3 +269,52 @@ func (m *mockLLM) TranslateMessage(prompt string, message string, lang string) (
 func (m *mockLLM) Name() string {
 	return m.name
 }
+
+// TestCommitMatchesLibrary checks that the commit command and the library finish the
+// answer of the provider the same way: validated, translated, scoped and formatted
+func TestCommitMatchesLibrary(t *testing.T) {
+	provider := newMockCompletionServer(t)
+	defer provider.Close()
+
+	_, dir, cleanup := setupTestRepo(t, git.Git)
+	defer cleanup()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "core"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "core", "config.go"), []byte("package core\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "."))
+
+	configPath, cleanupConfig := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: `+provider.URL+`
+  model: gpt-4o
+output:
+  lang: fr
+scopes:
+  map:
+    core/**: core
+`)
+	defer cleanupConfig()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	root.AddCommand(NewCommitCmd())
+	root.SetArgs([]string{"commit", "--dry-run"})
+	require.NoError(t, root.Execute())
+	entries, err := history.NewStore(filepath.Join(filepath.Dir(configPath), history.FileName)).List("")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	c, err := gptcomet.New(gptcomet.WithConfigFile(configPath), gptcomet.WithRepo(dir))
+	require.NoError(t, err)
+	message, err := c.GenerateForRepo(context.Background(), dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "fix(core): handle nil config", entries[0].Message)
+	assert.Equal(t, entries[0].Message, message)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/belingud/go-gptcomet/internal/committype"
 	"github.com/belingud/go-gptcomet/internal/config"
 	"github.com/belingud/go-gptcomet/internal/debug"
 
@@ -149,6 +150,12 @@ func NewConfigCmd() *cobra.Command {
 				value = args[1]
 			}
 
+			if provider, ok := strings.CutSuffix(args[0], ".model"); ok {
+				if models := cachedModels(cfgManager, provider); len(models) > 0 && !containsString(models, args[1]) {
+					fmt.Printf("Warning: model %s is not in the cached model list of %s, run `gptcomet models %s --refresh` to update it\n", args[1], provider, provider)
+				}
+			}
+
 			if err := cfgManager.Set(args[0], value); err != nil {
 				return err
 			}
@@ -156,6 +163,7 @@ func NewConfigCmd() *cobra.Command {
 			fmt.Printf("Successfully set '%s' to: %v\n", args[0], args[1])
 			return nil
 		},
+		ValidArgsFunction: completeConfigSet,
 	}
 
 	// path command
@@ -172,6 +180,9 @@ func NewConfigCmd() *cobra.Command {
 			}
 
 			fmt.Printf("Configuration file path: %s\n", cfgManager.GetPath())
+			if repoPath := cfgManager.GetRepoConfigPath(); repoPath != "" {
+				fmt.Printf("Repository configuration file path: %s\n", repoPath)
+			}
 			return nil
 		},
 	}
@@ -213,6 +224,7 @@ func NewConfigCmd() *cobra.Command {
 			}
 			return nil
 		},
+		ValidArgsFunction: completeConfigRemove,
 	}
 
 	// append command
@@ -279,3 +291,74 @@ func NewConfigCmd() *cobra.Command {
 	cmd.AddCommand(getCmd, listCmd, resetCmd, setCmd, pathCmd, removeCmd, appendCmd, keysCmd)
 	return cmd
 }
+
+// completeConfigSet completes the supported keys for `config set`, the cached model
+// names of the provider when the key is <provider>.model and the output formats
+func completeConfigSet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// The config command PersistentPreRunE does not run for completions
+	configPath, err := cmd.Root().PersistentFlags().GetString("config")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	cfgManager, err := config.New(configPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	switch len(args) {
+	case 0:
+		provider, _ := cfgManager.Get("provider")
+		providerStr, ok := provider.(string)
+		if !ok || providerStr == "" {
+			providerStr = "openai"
+		}
+		keys := cfgManager.GetSupportedKeys()
+		for i, key := range keys {
+			keys[i] = strings.Replace(key, "<provider>", providerStr, 1)
+		}
+		return keys, cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		switch args[0] {
+		case "output.style":
+			return config.OutputStyles, cobra.ShellCompDirectiveNoFileComp
+		case "output.emoji":
+			return []string{config.EmojiCode, config.EmojiUnicode}, cobra.ShellCompDirectiveNoFileComp
+		case "budget.on_exceed":
+			return []string{config.BudgetConfirm, config.BudgetRefuse}, cobra.ShellCompDirectiveNoFileComp
+		}
+		if provider, ok := strings.CutSuffix(args[0], ".model"); ok {
+			return cachedModels(cfgManager, provider), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigRemove completes the supported keys for `config remove`, and the
+// names of the commit types when the key is commit_types
+func completeConfigRemove(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 || args[0] != config.CommitTypesKey {
+		if len(args) == 0 {
+			return completeConfigSet(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	configPath, err := cmd.Root().PersistentFlags().GetString("config")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	cfgManager, err := config.New(configPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return committype.Names(cfgManager.GetCommitTypes()), cobra.ShellCompDirectiveNoFileComp
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -123,22 +123,63 @@ openai:
   <provider>.api_key
   <provider>.answer_path
   <provider>.completion_path
+  <provider>.daily_cost_budget
+  <provider>.daily_token_budget
   <provider>.extra_headers
+  <provider>.fallback
   <provider>.frequency_penalty
+  <provider>.max_prompt_tokens
   <provider>.max_tokens
   <provider>.model
+  <provider>.monthly_cost_budget
+  <provider>.monthly_token_budget
   <provider>.proxy
   <provider>.retries
   <provider>.temperature
   <provider>.top_p
+  branch.max_length
+  branch.prefixes
+  branch.ticket_pattern
+  budget.on_exceed
+  commit_types
   console.verbose
   file_ignore
+  output.emoji
   output.lang
   output.rich_template
+  output.style
+  prompt.ask
+  prompt.branch
   prompt.brief_commit_message
+  prompt.eval_judge
+  prompt.explain
+  prompt.profile
+  prompt.profiles.<name>.brief_commit_message
+  prompt.profiles.<name>.frequency_penalty
+  prompt.profiles.<name>.max_tokens
+  prompt.profiles.<name>.model
+  prompt.profiles.<name>.rich_commit_message
+  prompt.profiles.<name>.temperature
+  prompt.profiles.<name>.top_p
+  prompt.profiles.<name>.translation
+  prompt.resolve
+  prompt.review
   prompt.rich_commit_message
+  prompt.standup
+  prompt.stash
   prompt.translation
+  prompt.why
   provider
+  related_history.commits
+  related_history.enabled
+  scopes.auto
+  scopes.map.<pattern>
+  scopes.multiple
+  style.enabled
+  style.examples
+  style.samples
+  usage.prices.<model>.completion
+  usage.prices.<model>.prompt
 `,
 		},
 	}
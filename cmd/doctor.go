@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/pkg/types"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// checkStatus is the outcome of a single doctor check
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+var (
+	warnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	failStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	hintStyle = lipgloss.NewStyle().Faint(true)
+)
+
+// checkResult describes the result of a doctor check and how to fix it
+type checkResult struct {
+	Name   string
+	Status checkStatus
+	Detail string
+	Hint   string
+}
+
+// String renders the result as a checklist line, followed by the hint if any
+func (r checkResult) String() string {
+	var mark string
+	switch r.Status {
+	case checkPass:
+		mark = successStyle.Render("✔")
+	case checkWarn:
+		mark = warnStyle.Render("!")
+	default:
+		mark = failStyle.Render("✘")
+	}
+	line := fmt.Sprintf("%s %-12s %s", mark, r.Name, r.Detail)
+	if r.Hint != "" && r.Status != checkPass {
+		line += "\n    " + hintStyle.Render("hint: "+r.Hint)
+	}
+	return line
+}
+
+// checkBinary checks that a VCS binary is installed and reports its version
+func checkBinary(name string, required bool, hint string, versionArgs ...string) checkResult {
+	result := checkResult{Name: name}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		result.Status = checkWarn
+		if required {
+			result.Status = checkFail
+		}
+		result.Detail = "not found in PATH"
+		result.Hint = hint
+		return result
+	}
+
+	output, err := exec.Command(path, versionArgs...).Output()
+	if err != nil {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("%s is not working: %v", path, err)
+		result.Hint = hint
+		return result
+	}
+	result.Detail = strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+	return result
+}
+
+// checkConfig opens the config file without creating it.
+// It returns the config manager when the file can be loaded.
+func checkConfig(configPath string) (checkResult, *config.Manager) {
+	cfgManager, err := config.Open(configPath, ".")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		path, _ := config.ResolvePath(configPath)
+		return checkResult{
+			Name:   "config",
+			Status: checkFail,
+			Detail: fmt.Sprintf("%s does not exist", path),
+			Hint:   "run `gptcomet provider add` to create it",
+		}, nil
+	case err != nil:
+		return checkResult{
+			Name:   "config",
+			Status: checkFail,
+			Detail: err.Error(),
+			Hint:   "fix the YAML syntax or run `gptcomet config reset`",
+		}, nil
+	}
+	return checkResult{Name: "config", Detail: cfgManager.GetPath()}, cfgManager
+}
+
+// checkProviderConfig checks the active provider section of the config.
+// It returns the client config when it is complete enough to send requests.
+func checkProviderConfig(cfgManager *config.Manager) ([]checkResult, *types.ClientConfig) {
+	var results []checkResult
+
+	providerValue, _ := cfgManager.Get("provider")
+	provider, _ := providerValue.(string)
+	if provider == "" {
+		return append(results, checkResult{
+			Name:   "provider",
+			Status: checkFail,
+			Detail: "no active provider",
+			Hint:   "run `gptcomet provider add` or `gptcomet config set provider <name>`",
+		}), nil
+	}
+
+	section, _ := cfgManager.Get(provider)
+	providerConfig, ok := section.(map[string]interface{})
+	if !ok {
+		return append(results, checkResult{
+			Name:   "provider",
+			Status: checkFail,
+			Detail: fmt.Sprintf("%s has no configuration", provider),
+			Hint:   "run `gptcomet provider add` to configure it",
+		}), nil
+	}
+	results = append(results, checkResult{Name: "provider", Detail: provider})
+
+	apiKey, _ := providerConfig["api_key"].(string)
+	if apiKey == "" {
+		status := checkFail
+		if provider == "ollama" {
+			status = checkWarn
+		}
+		results = append(results, checkResult{
+			Name:   "api_key",
+			Status: status,
+			Detail: "not set",
+			Hint:   fmt.Sprintf("run `gptcomet config set %s.api_key <key>`", provider),
+		})
+	} else {
+		results = append(results, checkResult{Name: "api_key", Detail: config.MaskAPIKey(apiKey, 3)})
+	}
+
+	if proxy, _ := providerConfig["proxy"].(string); proxy != "" {
+		result := checkResult{Name: "proxy", Detail: proxy}
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			result.Status = checkFail
+			result.Detail = fmt.Sprintf("invalid proxy URL %q: %v", proxy, err)
+		} else if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5" {
+			result.Status = checkFail
+			result.Detail = fmt.Sprintf("unsupported proxy scheme %q", proxyURL.Scheme)
+		}
+		if result.Status == checkFail {
+			result.Hint = fmt.Sprintf("use an http://, https:// or socks5:// URL with `gptcomet config set %s.proxy <url>`", provider)
+		}
+		results = append(results, result)
+	}
+
+	if apiBase, _ := providerConfig["api_base"].(string); apiBase != "" {
+		result := checkResult{Name: "api_base", Detail: apiBase}
+		baseURL, err := url.Parse(apiBase)
+		if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+			result.Status = checkFail
+			result.Detail = fmt.Sprintf("invalid api_base %q", apiBase)
+			result.Hint = fmt.Sprintf("run `gptcomet config set %s.api_base <url>`", provider)
+		}
+		results = append(results, result)
+	}
+
+	for _, r := range results {
+		if r.Status == checkFail {
+			return results, nil
+		}
+	}
+
+	clientConfig, err := cfgManager.GetClientConfig()
+	if err != nil {
+		return append(results, checkResult{
+			Name:   "client",
+			Status: checkFail,
+			Detail: err.Error(),
+			Hint:   "check the fallback provider, budget and model settings with `gptcomet config list`",
+		}), nil
+	}
+	return results, clientConfig
+}
+
+// checkConnection checks that the API base can be reached
+func checkConnection(ctx context.Context, c *client.Client, apiBase string) checkResult {
+	if err := c.CheckConnection(ctx); err != nil {
+		return checkResult{
+			Name:   "network",
+			Status: checkFail,
+			Detail: err.Error(),
+			Hint:   "check your network, proxy and api_base settings",
+		}
+	}
+	return checkResult{Name: "network", Detail: fmt.Sprintf("%s is reachable", apiBase)}
+}
+
+// checkCompletion sends a minimal completion to verify authentication and the model name
+func checkCompletion(ctx context.Context, c *client.Client, model string) checkResult {
+	start := time.Now()
+	if _, err := c.Chat(ctx, "Reply with OK.", nil); err != nil {
+		return checkResult{
+			Name:   "completion",
+			Status: checkFail,
+			Detail: err.Error(),
+			Hint:   "check that the api_key is valid and the model name is available to your account",
+		}
+	}
+	return checkResult{
+		Name:   "completion",
+		Detail: fmt.Sprintf("%s answered in %s", model, time.Since(start).Round(time.Millisecond)),
+	}
+}
+
+// NewDoctorCmd creates a new doctor command
+func NewDoctorCmd() *cobra.Command {
+	var (
+		skipRequest bool
+		timeout     int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the environment and provider configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var results []checkResult
+			report := func(r checkResult) {
+				results = append(results, r)
+				fmt.Println(r)
+			}
+
+			report(checkBinary("git", true, "install git from https://git-scm.com", "--version"))
+			report(checkBinary("svn", false, "install subversion if you want to use --svn", "--version", "--quiet"))
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			configResult, cfgManager := checkConfig(configPath)
+			report(configResult)
+			if cfgManager != nil {
+				providerResults, clientConfig := checkProviderConfig(cfgManager)
+				for _, r := range providerResults {
+					report(r)
+				}
+
+				if clientConfig != nil {
+					ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+					defer cancel()
+
+					c := client.New(clientConfig)
+					network := checkConnection(ctx, c, clientConfig.APIBase)
+					report(network)
+					if network.Status == checkPass && !skipRequest {
+						report(checkCompletion(ctx, c, clientConfig.Model))
+					}
+				}
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Status == checkFail {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("doctor found %d problem(s)", failed)
+			}
+			fmt.Println("\nEverything looks good!")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&skipRequest, "skip-request", false, "Do not send a test completion to the provider")
+	cmd.Flags().IntVar(&timeout, "timeout", 30, "Timeout in seconds for the network checks")
+
+	return cmd
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDoctorCmd(t *testing.T) {
+	cmd := NewDoctorCmd()
+	require.NotNil(t, cmd)
+	assert.NotNil(t, cmd.Flags().Lookup("skip-request"))
+	assert.NotNil(t, cmd.Flags().Lookup("timeout"))
+}
+
+func TestCheckBinary(t *testing.T) {
+	result := checkBinary("gptcomet-missing-binary", true, "install it", "--version")
+	assert.Equal(t, checkFail, result.Status)
+	assert.Contains(t, result.String(), "hint: install it")
+
+	result = checkBinary("gptcomet-missing-binary", false, "install it", "--version")
+	assert.Equal(t, checkWarn, result.Status)
+}
+
+func TestCheckConfig(t *testing.T) {
+	// A missing config file is reported, not created
+	missing := filepath.Join(t.TempDir(), "config.yaml")
+	result, cfgManager := checkConfig(missing)
+	assert.Equal(t, checkFail, result.Status)
+	assert.Contains(t, result.Detail, "does not exist")
+	assert.Nil(t, cfgManager)
+	assert.NoFileExists(t, missing)
+
+	invalid := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("provider: [openai"), 0644))
+	result, cfgManager = checkConfig(invalid)
+	assert.Equal(t, checkFail, result.Status)
+	assert.Contains(t, result.Hint, "YAML")
+	assert.Nil(t, cfgManager)
+
+	configPath, cleanup := testutils.TestConfig(t, "provider: openai\n")
+	defer cleanup()
+	result, cfgManager = checkConfig(configPath)
+	assert.Equal(t, checkPass, result.Status)
+	assert.NotNil(t, cfgManager)
+}
+
+func TestCheckProviderConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantFail   string
+		wantClient bool
+	}{
+		{
+			name: "valid",
+			content: `
+provider: openai
+openai:
+  api_key: sk-abcdefghijkl
+  api_base: https://api.openai.com/v1
+  proxy: socks5://127.0.0.1:1080
+`,
+			wantClient: true,
+		},
+		{
+			name: "missing api key",
+			content: `
+provider: openai
+openai:
+  api_base: https://api.openai.com/v1
+`,
+			wantFail: "api_key",
+		},
+		{
+			name: "invalid proxy",
+			content: `
+provider: openai
+openai:
+  api_key: sk-abcdefghijkl
+  proxy: ftp://proxy
+`,
+			wantFail: "proxy",
+		},
+		{
+			name: "invalid api base",
+			content: `
+provider: openai
+openai:
+  api_key: sk-abcdefghijkl
+  api_base: not a url
+`,
+			wantFail: "api_base",
+		},
+		{
+			name: "missing fallback provider",
+			content: `
+provider: openai
+openai:
+  api_key: sk-abcdefghijkl
+  fallback: missing
+`,
+			wantFail: "client",
+		},
+		{
+			name: "invalid budget setting",
+			content: `
+provider: openai
+budget:
+  on_exceed: deny
+openai:
+  api_key: sk-abcdefghijkl
+`,
+			wantFail: "client",
+		},
+		{
+			name: "unconfigured provider",
+			content: `
+provider: mistral
+`,
+			wantFail: "provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath, cleanup := testutils.TestConfig(t, tt.content)
+			defer cleanup()
+			cfgManager, err := config.New(configPath)
+			require.NoError(t, err)
+
+			results, clientConfig := checkProviderConfig(cfgManager)
+			assert.Equal(t, tt.wantClient, clientConfig != nil)
+
+			var failed []string
+			for _, r := range results {
+				if r.Status == checkFail {
+					failed = append(failed, r.Name)
+				}
+				if r.Name == "api_key" && r.Status == checkPass {
+					assert.Equal(t, "sk-abc*********", r.Detail)
+				}
+			}
+			if tt.wantFail == "" {
+				assert.Empty(t, failed)
+			} else {
+				assert.Equal(t, []string{tt.wantFail}, failed)
+			}
+		})
+	}
+}
+
+func TestCheckNetworkAndCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"OK"}}]}`))
+	}))
+	defer server.Close()
+
+	newClient := func(apiBase, key string) *client.Client {
+		return client.New(&types.ClientConfig{
+			Provider: "openai",
+			APIBase:  apiBase,
+			APIKey:   key,
+			Model:    "gpt-4o",
+			Timeout:  10,
+		})
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, checkPass, checkConnection(ctx, newClient(server.URL, "good"), server.URL).Status)
+	assert.Equal(t, checkPass, checkCompletion(ctx, newClient(server.URL, "good"), "gpt-4o").Status)
+
+	result := checkCompletion(ctx, newClient(server.URL, "bad"), "gpt-4o")
+	assert.Equal(t, checkFail, result.Status)
+	assert.Contains(t, result.Detail, "401")
+
+	assert.Equal(t, checkFail, checkConnection(ctx, newClient("http://127.0.0.1:1", "good"), "http://127.0.0.1:1").Status)
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/eval"
+	"github.com/belingud/go-gptcomet/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// evalTarget is a provider/model pair under evaluation
+type evalTarget struct {
+	Name   string
+	Config *types.ClientConfig
+}
+
+// parseEvalTarget resolves "provider" or "provider/model" to a client config,
+// overriding the API base when apiBase is set
+func parseEvalTarget(cfgManager *config.Manager, spec, apiBase string) (evalTarget, error) {
+	provider, model, _ := strings.Cut(spec, "/")
+	clientConfig, err := cfgManager.GetProviderClientConfig(provider)
+	if err != nil {
+		return evalTarget{}, err
+	}
+	if model != "" {
+		clientConfig.Model = model
+	}
+	if apiBase != "" {
+		clientConfig.APIBase = apiBase
+	}
+	// Evaluation runs must not fall back to other providers, or the comparison is meaningless
+	clientConfig.Fallback = nil
+	return evalTarget{Name: clientConfig.Provider + "/" + clientConfig.Model, Config: clientConfig}, nil
+}
+
+// judgeOutput asks the judge model to rate output, returning a score between 0 and 1
+func judgeOutput(ctx context.Context, judge *client.Client, prompt string, sample eval.Sample, output string) (float64, error) {
+	judgePrompt, err := renderPrompt(prompt, promptVars{
+		"placeholder": sample.Diff,
+		"diff":        sample.Diff,
+		"reference":   sample.Reference,
+		"candidate":   output,
+	})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := judge.Chat(ctx, judgePrompt, nil)
+	if err != nil {
+		return 0, err
+	}
+	return eval.ParseJudgeScore(resp.Content)
+}
+
+// formatEvalSummaries renders the comparison table of the evaluated targets
+func formatEvalSummaries(summaries []eval.Summary) string {
+	var b strings.Builder
+	percent := func(value float64, samples int) string {
+		if samples == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%%", value*100)
+	}
+
+	fmt.Fprintf(&b, "%-32s %7s %6s %7s %7s %7s %7s %7s %6s %9s\n",
+		"target", "samples", "failed", "length", "len ok", "conv", "type", "overlap", "judge", "latency")
+	for _, s := range summaries {
+		ok := s.Samples - s.Failures
+		fmt.Fprintf(&b, "%-32s %7d %6d %7.1f %7s %7s %7s %7.2f %6s %9s\n",
+			s.Target, s.Samples, s.Failures, s.AvgLength,
+			percent(s.LengthScore, ok), percent(s.Conventional, ok), percent(s.TypeAgreement, s.TypeSamples),
+			s.Overlap, percent(s.Judge, s.JudgeSamples), s.AvgLatency.Round(time.Millisecond))
+	}
+	return b.String()
+}
+
+// NewEvalCmd creates a new eval command
+func NewEvalCmd() *cobra.Command {
+	var (
+		models  []string
+		judge   string
+		rich    bool
+		apiBase string
+		limit   int
+		output  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "eval <dataset.jsonl>",
+		Short: "Evaluate the commit prompt against a dataset of diffs and reference messages",
+		Long: `Evaluate the configured commit prompt against a JSONL dataset.
+
+Each line of the dataset is an object with a "diff" and the "reference" commit message
+written for it, and an optional "id". Every provider/model pair generates a message for
+each diff, scored by subject length, conventional format, commit type agreement with the
+reference and word overlap, and optionally by an LLM judge.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			samples, err := eval.LoadDataset(args[0])
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(samples) > limit {
+				samples = samples[:limit]
+			}
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			if len(models) == 0 {
+				active, _ := cfgManager.Get("provider")
+				provider, ok := active.(string)
+				if !ok || provider == "" {
+					return fmt.Errorf("provider not set, pass --model provider/model")
+				}
+				models = []string{provider}
+			}
+			var targets []evalTarget
+			for _, spec := range models {
+				target, err := parseEvalTarget(cfgManager, spec, apiBase)
+				if err != nil {
+					return fmt.Errorf("invalid target %s: %w", spec, err)
+				}
+				targets = append(targets, target)
+			}
+
+			var judgeClient *client.Client
+			if judge != "" {
+				target, err := parseEvalTarget(cfgManager, judge, apiBase)
+				if err != nil {
+					return fmt.Errorf("invalid judge %s: %w", judge, err)
+				}
+				judgeClient = client.New(target.Config)
+			}
+
+			prompt := cfgManager.GetPrompt(nil, rich)
+			judgePrompt := cfgManager.GetNamedPrompt("eval_judge")
+
+			ctx := context.Background()
+			var results []eval.Result
+			for _, target := range targets {
+				c := client.New(target.Config)
+				for i, sample := range samples {
+					fmt.Fprintf(cmd.ErrOrStderr(), "\r%s: %d/%d", target.Name, i+1, len(samples))
+
+					commitPrompt, err := renderPrompt(prompt, commitPromptVars(cfgManager, nil, "", sample.Diff, ""))
+					if err != nil {
+						return err
+					}
+
+					start := time.Now()
+					resp, err := c.Chat(ctx, commitPrompt, nil)
+					if err != nil {
+						debug.Printf("Sample %s failed on %s: %v", sample.ID, target.Name, err)
+						results = append(results, eval.Result{SampleID: sample.ID, Target: target.Name, Error: err.Error()})
+						continue
+					}
+
+					result := eval.Score(sample, target.Name, strings.TrimSpace(resp.Content), time.Since(start))
+					if judgeClient != nil {
+						score, err := judgeOutput(ctx, judgeClient, judgePrompt, sample, result.Output)
+						if err != nil {
+							debug.Printf("Failed to judge sample %s: %v", sample.ID, err)
+						} else {
+							result.Judge = &score
+						}
+					}
+					results = append(results, result)
+				}
+				fmt.Fprintln(cmd.ErrOrStderr())
+			}
+
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer f.Close()
+				encoder := json.NewEncoder(f)
+				for _, result := range results {
+					if err := encoder.Encode(result); err != nil {
+						return fmt.Errorf("failed to write results: %w", err)
+					}
+				}
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), formatEvalSummaries(eval.Summarize(results)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&models, "model", "m", nil, "Provider or provider/model to evaluate, can be repeated (default: the active provider)")
+	cmd.Flags().StringVar(&judge, "judge", "", "Provider or provider/model used as LLM judge")
+	cmd.Flags().BoolVarP(&rich, "rich", "r", false, "Evaluate the rich commit message prompt")
+	cmd.Flags().StringVar(&apiBase, "api-base", "", "Send every request to this API base, e.g. a local mock server")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Evaluate only the first n samples")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the per-sample results as JSONL to this file")
+
+	return cmd
+}
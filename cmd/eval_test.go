@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockCompletionServer returns an OpenAI compatible server answering judge prompts
+// with a score and commit prompts with a message depending on the requested model
+func newMockCompletionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Model    string `json:"model"`
+			Stream   bool   `json:"stream"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.Unmarshal(body, &req))
+		prompt := req.Messages[len(req.Messages)-1].Content
+
+		answer := "fix: handle nil config"
+		switch {
+		case req.Model == "broken":
+			http.Error(w, `{"error":"overloaded"}`, http.StatusServiceUnavailable)
+			return
+		case strings.Contains(prompt, "Candidate message:"):
+			answer = "8"
+		case req.Model == "weak":
+			answer = "Changed some files"
+		}
+		if req.Stream {
+			// Send the answer word by word, then the usage
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i, word := range strings.SplitAfter(answer, " ") {
+				fmt.Fprintf(w, "data: {\"choices\":[{\"index\":%d,\"delta\":{\"content\":%q}}]}\n\n", i, word)
+			}
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":100,\"completion_tokens\":6,\"total_tokens\":106}}\n\ndata: [DONE]\n\n")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": answer}}},
+			"usage":   map[string]int{"prompt_tokens": 100, "completion_tokens": 6, "total_tokens": 106},
+		})
+	}))
+}
+
+func TestEvalCmd(t *testing.T) {
+	server := newMockCompletionServer(t)
+	defer server.Close()
+
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: https://example.invalid/v1
+  model: gpt-4o
+`)
+	defer cleanup()
+
+	dir := t.TempDir()
+	dataset := filepath.Join(dir, "dataset.jsonl")
+	require.NoError(t, os.WriteFile(dataset, []byte(
+		`{"id":"nil","diff":"diff --git a/config.go b/config.go","reference":"fix: handle nil config"}`+"\n"+
+			`{"id":"docs","diff":"diff --git a/README.md b/README.md","reference":"docs: describe eval"}`+"\n"), 0644))
+	results := filepath.Join(dir, "results.jsonl")
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	root.AddCommand(NewEvalCmd())
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetArgs([]string{"eval", dataset,
+		"--model", "openai/gpt-4o", "--model", "openai/weak",
+		"--judge", "openai", "--api-base", server.URL, "--output", results})
+	require.NoError(t, root.Execute())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^openai/gpt-4o\s+2\s+0\s+22\.0\s+100%\s+100%\s+50%\s+0\.\d+\s+78%`, lines[1])
+	assert.Regexp(t, `^openai/weak\s+2\s+0\s+18\.0\s+100%\s+0%\s+0%`, lines[2])
+
+	data, err := os.ReadFile(results)
+	require.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(string(data), "\n"))
+	assert.Contains(t, string(data), `"sample_id":"docs"`)
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+// historyStore returns the generation history stored next to the config file
+func historyStore(cfgManager *config.Manager) *history.Store {
+	return history.NewStore(filepath.Join(filepath.Dir(cfgManager.GetPath()), history.FileName))
+}
+
+// historyRepo returns the path identifying the repository of repoPath in the history
+func historyRepo(vcs git.VCS, repoPath string) string {
+	if gitVCS, ok := vcs.(*git.GitVCS); ok {
+		if root, err := gitVCS.GetRepoRoot(repoPath); err == nil && root != "" {
+			return root
+		}
+	}
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return repoPath
+	}
+	return abs
+}
+
+// saveHistory saves entry, only logging failures since the history must never block a commit
+func saveHistory(store *history.Store, entry *history.Entry) {
+	var err error
+	if entry.ID == "" {
+		err = store.Add(entry)
+	} else {
+		err = store.Update(*entry)
+	}
+	if err != nil {
+		debug.Printf("Failed to save history: %v", err)
+	}
+}
+
+// formatHistoryEntry renders the details of a history entry
+func formatHistoryEntry(entry history.Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ID:       %s\n", entry.ID)
+	fmt.Fprintf(&b, "Date:     %s\n", entry.Time.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "Repo:     %s\n", entry.Repo)
+	fmt.Fprintf(&b, "Diff:     %s\n", entry.DiffHash)
+	if entry.Profile != "" {
+		fmt.Fprintf(&b, "Profile:  %s\n", entry.Profile)
+	}
+	fmt.Fprintf(&b, "Model:    %s/%s\n", entry.Provider, entry.Model)
+	fmt.Fprintf(&b, "Status:   %s\n", entry.Status)
+	if entry.CommitHash != "" {
+		fmt.Fprintf(&b, "Commit:   %s\n", entry.CommitHash)
+	}
+	fmt.Fprintf(&b, "\n%s\n", entry.Message)
+	if entry.Final != "" {
+		fmt.Fprintf(&b, "\nEdited to:\n%s\n", entry.Final)
+	}
+	return b.String()
+}
+
+// newHistoryStore loads the config manager and opens the history store
+func newHistoryStore(cmd *cobra.Command) (*config.Manager, *history.Store, error) {
+	// Get config path from root command
+	configPath, err := cmd.Root().PersistentFlags().GetString("config")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+
+	// Create config manager
+	cfgManager, err := config.New(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+	return cfgManager, historyStore(cfgManager), nil
+}
+
+// NewHistoryCmd creates a new history command
+func NewHistoryCmd() *cobra.Command {
+	var (
+		limit   int
+		all     bool
+		edit    bool
+		useSVN  bool
+		autoYes bool
+	)
+
+	listRunE := func(cmd *cobra.Command, args []string) error {
+		_, store, err := newHistoryStore(cmd)
+		if err != nil {
+			return err
+		}
+
+		repo := ""
+		if !all {
+			repoPath, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+			repo = historyRepo(&git.GitVCS{}, repoPath)
+		}
+
+		entries, err := store.List(repo)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No generated messages found")
+			return nil
+		}
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+		for _, entry := range entries {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s  %s  %-9s %s\n",
+				successStyle.Render(entry.ID), entry.Time.Format("2006-01-02 15:04"), entry.Status, entry.Subject())
+		}
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List, show and reuse generated commit messages",
+		Args:  cobra.NoArgs,
+		RunE:  listRunE,
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of entries to list")
+	cmd.Flags().BoolVar(&all, "all", false, "List entries of every repository")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List generated commit messages of the current repository",
+		Args:  cobra.NoArgs,
+		RunE:  listRunE,
+	}
+	listCmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of entries to list")
+	listCmd.Flags().BoolVar(&all, "all", false, "List entries of every repository")
+
+	showCmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a generated commit message",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := newHistoryStore(cmd)
+			if err != nil {
+				return err
+			}
+			entry, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), formatHistoryEntry(entry))
+			return nil
+		},
+	}
+
+	reuseCmd := &cobra.Command{
+		Use:   "reuse <id>",
+		Short: "Commit the staged changes with a previously generated message",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgManager, store, err := newHistoryStore(cmd)
+			if err != nil {
+				return err
+			}
+			entry, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			repoPath, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+
+			vcsType := git.Git
+			if useSVN {
+				vcsType = git.SVN
+			}
+			vcs, err := git.NewVCS(vcsType)
+			if err != nil {
+				return fmt.Errorf("failed to create VCS (%s): %w", vcsType, err)
+			}
+
+			hasStagedChanges, err := vcs.HasStagedChanges(repoPath)
+			if err != nil {
+				return fmt.Errorf("failed to check staged changes: %w", err)
+			}
+			if !hasStagedChanges {
+				return fmt.Errorf("no staged changes found")
+			}
+
+			if repo := historyRepo(vcs, repoPath); repo != entry.Repo {
+				fmt.Printf("Warning: this message was generated in %s\n", entry.Repo)
+			}
+			if diff, err := vcs.GetStagedDiffFiltered(repoPath, cfgManager); err == nil && history.HashDiff(diff) != entry.DiffHash {
+				fmt.Println("Warning: the staged changes differ from the ones this message was generated for")
+			}
+
+			message := entry.Text()
+			if edit {
+				edited, err := editText(message)
+				if err != nil {
+					return fmt.Errorf("failed to edit message: %w", err)
+				}
+				message = edited
+			}
+			fmt.Printf("\nCommit message:\n%s\n", formatCommitMessage(message))
+
+			if !autoYes {
+				fmt.Print("\nWould you like to create this commit? ([Y]es/[n]o): ")
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				answer = strings.ToLower(strings.TrimSpace(answer))
+				if answer != "" && answer != "y" && answer != "yes" {
+					fmt.Println("Operation cancelled")
+					return nil
+				}
+			}
+
+			if err := vcs.CreateCommit(repoPath, message); err != nil {
+				entry.Status = history.StatusFailed
+				saveHistory(store, &entry)
+				return fmt.Errorf("failed to create commit: %w", err)
+			}
+
+			entry.Status = history.StatusAccepted
+			if message != entry.Message {
+				entry.Status = history.StatusEdited
+				entry.Final = message
+			}
+			if hash, err := vcs.GetLastCommitHash(repoPath); err == nil {
+				entry.CommitHash = strings.TrimSpace(hash)
+			}
+			saveHistory(store, &entry)
+
+			commitInfo, err := vcs.GetCommitInfo(repoPath, entry.CommitHash)
+			if err != nil {
+				return fmt.Errorf("failed to get commit info: %w", err)
+			}
+			fmt.Printf("\nSuccessfully created commit:\n%s\n", commitInfo)
+			return nil
+		},
+	}
+	reuseCmd.Flags().BoolVarP(&edit, "edit", "e", false, "Edit the message before committing")
+	reuseCmd.Flags().BoolVarP(&autoYes, "yes", "y", false, "Commit without asking")
+	reuseCmd.Flags().BoolVar(&useSVN, "svn", false, "Use SVN instead of Git")
+
+	cmd.AddCommand(listCmd, showCmd, reuseCmd)
+	return cmd
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/history"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistoryCmd(t *testing.T) {
+	cmd := NewHistoryCmd()
+	require.NotNil(t, cmd)
+	for _, name := range []string{"list", "show", "reuse"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		assert.Equal(t, name, sub.Name())
+	}
+	reuse, _, _ := cmd.Find([]string{"reuse"})
+	assert.NotNil(t, reuse.Flags().Lookup("edit"))
+	assert.NotNil(t, reuse.Flags().Lookup("yes"))
+}
+
+func TestHistoryListAndShow(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, "provider: openai\n")
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	entry := &history.Entry{
+		Time:     time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local),
+		Repo:     "/src/api",
+		DiffHash: "0123456789ab",
+		Profile:  "rich",
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Message:  "feat: add login\n\nAdd the login endpoint",
+		Status:   history.StatusRejected,
+	}
+	require.NoError(t, historyStore(cfgManager).Add(entry))
+
+	run := func(args ...string) string {
+		root := &cobra.Command{Use: "gptcomet"}
+		root.PersistentFlags().String("config", configPath, "")
+		root.AddCommand(NewHistoryCmd())
+		var buf bytes.Buffer
+		root.SetOut(&buf)
+		root.SetArgs(append([]string{"history"}, args...))
+		require.NoError(t, root.Execute())
+		return buf.String()
+	}
+
+	output := run("list", "--all")
+	assert.Contains(t, output, entry.ID)
+	assert.Contains(t, output, "rejected")
+	assert.Contains(t, output, "feat: add login")
+	assert.NotContains(t, output, "login endpoint")
+
+	output = run("show", entry.ID)
+	assert.Contains(t, output, "Profile:  rich")
+	assert.Contains(t, output, "Model:    openai/gpt-4o")
+	assert.Contains(t, output, "Add the login endpoint")
+}
+
+func TestHistoryReuseStoresCommitHash(t *testing.T) {
+	_, dir, cleanup := setupTestRepo(t, git.Git)
+	defer cleanup()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "login.go"), []byte("package login\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "login.go"))
+
+	configPath, cleanupConfig := testutils.TestConfig(t, "provider: openai\n")
+	defer cleanupConfig()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+	store := historyStore(cfgManager)
+	entry := &history.Entry{Repo: dir, Message: "feat: add login", Status: history.StatusRejected}
+	require.NoError(t, store.Add(entry))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	root.AddCommand(NewHistoryCmd())
+	root.SetArgs([]string{"history", "reuse", entry.ID, "--yes"})
+	require.NoError(t, root.Execute())
+
+	head, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	stored, err := store.Get(entry.ID)
+	require.NoError(t, err)
+	assert.Equal(t, history.StatusAccepted, stored.Status)
+	assert.Equal(t, strings.TrimSpace(string(head)), stored.CommitHash)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+
+	"golang.org/x/term"
+)
+
+// ConfirmLimit asks on the terminal whether a request exceeding a limit should be sent anyway.
+// It refuses when stdin is not a terminal.
+func ConfirmLimit(err *client.LimitError) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	return confirmLimit(os.Stdin, os.Stderr, err)
+}
+
+// confirmLimit prints the exceeded limit to out and reads a y/N answer from in
+func confirmLimit(in io.Reader, out io.Writer, err *client.LimitError) bool {
+	fmt.Fprintf(out, "%v\nSend the request anyway? (y/N): ", err)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
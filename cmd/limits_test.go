@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmLimit(t *testing.T) {
+	limitErr := &client.LimitError{Provider: "openai", Limit: "daily_token_budget", Used: 90, Requested: 20, Max: 100}
+
+	var out bytes.Buffer
+	assert.True(t, confirmLimit(strings.NewReader("y\n"), &out, limitErr))
+	assert.Contains(t, out.String(), "openai.daily_token_budget: 90 used + 20 estimated > 100")
+	assert.Contains(t, out.String(), "(y/N)")
+
+	assert.False(t, confirmLimit(strings.NewReader("\n"), &out, limitErr))
+	assert.False(t, confirmLimit(strings.NewReader(""), &out, limitErr))
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/llm"
+
+	"github.com/spf13/cobra"
+)
+
+// modelsCacheTTL is how long a fetched model list is reused before querying the provider again
+const modelsCacheTTL = 24 * time.Hour
+
+// modelsCacheEntry is the cached model list of a provider
+type modelsCacheEntry struct {
+	Models    []string  `json:"models"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// modelsCachePath returns the model list cache file, next to the config file
+func modelsCachePath(cfgManager *config.Manager) string {
+	return filepath.Join(filepath.Dir(cfgManager.GetPath()), "models_cache.json")
+}
+
+// loadModelsCache reads the cached model lists keyed by provider
+func loadModelsCache(path string) (map[string]modelsCacheEntry, error) {
+	cache := make(map[string]modelsCacheEntry)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("failed to read models cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse models cache: %w", err)
+	}
+	return cache, nil
+}
+
+// saveModelsCache writes the cached model lists
+func saveModelsCache(path string, cache map[string]modelsCacheEntry) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal models cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write models cache: %w", err)
+	}
+	return nil
+}
+
+// cachedModels returns the cached models of provider regardless of their age,
+// or nil if the provider was never listed
+func cachedModels(cfgManager *config.Manager, provider string) []string {
+	cache, err := loadModelsCache(modelsCachePath(cfgManager))
+	if err != nil {
+		debug.Printf("Failed to load models cache: %v", err)
+		return nil
+	}
+	return cache[provider].Models
+}
+
+// configuredProviders returns the registered providers that have a config section
+func configuredProviders(cfgManager *config.Manager) []string {
+	var providers []string
+	for _, name := range llm.GetProviders() {
+		if section, ok := cfgManager.Get(name); ok {
+			if _, ok := section.(map[string]interface{}); ok {
+				providers = append(providers, name)
+			}
+		}
+	}
+	return providers
+}
+
+// NewModelsCmd creates a new models command
+func NewModelsCmd() *cobra.Command {
+	var (
+		all     bool
+		refresh bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "models [provider]",
+		Short: "List the models available from a provider",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			var providers []string
+			switch {
+			case len(args) == 1:
+				providers = args
+			case all:
+				providers = configuredProviders(cfgManager)
+			default:
+				active, _ := cfgManager.Get("provider")
+				provider, ok := active.(string)
+				if !ok || provider == "" {
+					return fmt.Errorf("provider not set")
+				}
+				providers = []string{provider}
+			}
+
+			cachePath := modelsCachePath(cfgManager)
+			cache, err := loadModelsCache(cachePath)
+			if err != nil {
+				return err
+			}
+
+			updated := false
+			for _, provider := range providers {
+				clientConfig, err := cfgManager.GetProviderClientConfig(provider)
+				if err != nil {
+					fmt.Printf("%s: %v\n", provider, err)
+					continue
+				}
+
+				entry, ok := cache[provider]
+				if refresh || !ok || time.Since(entry.FetchedAt) > modelsCacheTTL {
+					debug.Printf("Fetching models for %s", provider)
+					models, err := client.New(clientConfig).ListModels(context.Background())
+					if err != nil {
+						fmt.Printf("%s: %v\n", provider, err)
+						continue
+					}
+					entry = modelsCacheEntry{Models: models, FetchedAt: time.Now()}
+					cache[provider] = entry
+					updated = true
+				}
+
+				fmt.Printf("%s:\n", provider)
+				for _, model := range entry.Models {
+					if model == clientConfig.Model {
+						fmt.Printf("  %s\n", successStyle.Render(model+" (configured)"))
+					} else {
+						fmt.Printf("  %s\n", model)
+					}
+				}
+			}
+
+			if updated {
+				return saveModelsCache(cachePath, cache)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "List models of every configured provider")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Ignore the cache and query the providers again")
+
+	return cmd
+}
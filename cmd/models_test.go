@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewModelsCmd(t *testing.T) {
+	cmd := NewModelsCmd()
+	require.NotNil(t, cmd)
+	assert.NotNil(t, cmd.Flags().Lookup("all"))
+	assert.NotNil(t, cmd.Flags().Lookup("refresh"))
+	assert.Error(t, cmd.Args(cmd, []string{"openai", "ollama"}))
+}
+
+func TestModelsCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models_cache.json")
+
+	cache, err := loadModelsCache(path)
+	require.NoError(t, err)
+	assert.Empty(t, cache)
+
+	cache["openai"] = modelsCacheEntry{Models: []string{"gpt-4o", "gpt-4o-mini"}, FetchedAt: time.Now()}
+	require.NoError(t, saveModelsCache(path, cache))
+
+	loaded, err := loadModelsCache(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, loaded["openai"].Models)
+}
+
+func TestCompleteConfigSet(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+ollama:
+  model: llama3
+`)
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+	require.NoError(t, saveModelsCache(modelsCachePath(cfgManager), map[string]modelsCacheEntry{
+		"ollama": {Models: []string{"llama3", "qwen2"}, FetchedAt: time.Now()},
+	}))
+
+	assert.ElementsMatch(t, []string{"openai", "ollama"}, configuredProviders(cfgManager))
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	cmd := &cobra.Command{Use: "set"}
+	root.AddCommand(cmd)
+
+	keys, _ := completeConfigSet(cmd, nil, "")
+	assert.Contains(t, keys, "openai.model")
+
+	models, directive := completeConfigSet(cmd, []string{"ollama.model"}, "")
+	assert.Equal(t, []string{"llama3", "qwen2"}, models)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	models, _ = completeConfigSet(cmd, []string{"output.lang"}, "")
+	assert.Empty(t, models)
+}
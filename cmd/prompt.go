@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/belingud/go-gptcomet/internal/commitmsg"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/prompt"
+)
+
+// recentCommitsCount is the number of recent commit subjects available to commit prompts
+const recentCommitsCount = 10
+
+// maxRelatedFiles is the maximum number of staged files looked up for the related history
+const maxRelatedFiles = 20
+
+// promptVars are the variables of a prompt by name
+type promptVars = prompt.Vars
+
+// errInvalidPrompt is wrapped by the errors of prompts that cannot be rendered
+var errInvalidPrompt = errors.New("invalid prompt")
+
+// renderPrompt renders the prompt template text with vars
+func renderPrompt(text string, vars promptVars) (string, error) {
+	rendered, err := prompt.Render(text, vars)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errInvalidPrompt, err)
+	}
+	return rendered, nil
+}
+
+// commitPromptVars returns the variables of the commit message prompts. The repository
+// details are only filled when vcs is a git repository, and are left empty on errors.
+func commitPromptVars(cfgManager *config.Manager, vcs git.VCS, repoPath, diff, hint string) promptVars {
+	vars := prompt.Commit{
+		Diff:        diff,
+		Hint:        hint,
+		Types:       cfgManager.GetCommitTypes(),
+		OutputStyle: cfgManager.GetOutputStyle().Style,
+	}
+
+	richTemplate, _ := cfgManager.Get("output.rich_template")
+	vars.RichTemplate, _ = richTemplate.(string)
+	if lang, err := outputLanguage(cfgManager); err == nil {
+		vars.Lang = lang
+	}
+
+	if gitVCS, ok := vcs.(*git.GitVCS); ok {
+		vars.Repo = filepath.Base(historyRepo(gitVCS, repoPath))
+		if files, err := gitVCS.GetStagedFiles(repoPath); err == nil {
+			vars.StagedFiles = files
+		}
+		if branch, err := gitVCS.GetCurrentBranch(repoPath); err == nil {
+			vars.Branch = branch
+		}
+		if commits, err := gitVCS.GetRecentCommits(repoPath, recentCommitsCount); err == nil {
+			for _, commit := range commits {
+				vars.RecentCommits = append(vars.RecentCommits, commit.Subject)
+			}
+		} else {
+			debug.Printf("Failed to get recent commits: %v", err)
+		}
+		if scopes, _, err := commitmsg.FileScopes(cfgManager, gitVCS, repoPath, vars.StagedFiles); err == nil {
+			vars.Scopes = scopes
+		} else {
+			debug.Printf("Failed to get the scopes of the staged files: %v", err)
+		}
+		if related := cfgManager.GetRelatedHistoryConfig(); related.Enabled {
+			ignore := git.IgnorePatterns(cfgManager)
+			vars.RelatedHistory = relatedHistory(gitVCS, repoPath, vars.StagedFiles, ignore, related.Commits)
+		}
+		if cfgManager.GetStyleConfig().Enabled {
+			if profile, err := learnedStyle(cfgManager, gitVCS, repoPath, false); err == nil {
+				vars.Style = profile.Describe()
+				vars.StyleExamples = profile.Examples
+			} else {
+				debug.Printf("Failed to learn the commit style: %v", err)
+			}
+		}
+	}
+	return vars.Vars()
+}
+
+// relatedHistory returns the subjects of the last commits touching each staged file,
+// skipping ignored files and files without history
+func relatedHistory(vcs *git.GitVCS, repoPath string, files, ignore []string, commits int) prompt.History {
+	// Staged files are relative to the repository root
+	root := historyRepo(vcs, repoPath)
+	var history prompt.History
+	looked := 0
+	for _, file := range files {
+		if git.ShouldIgnoreFile(file, ignore) {
+			continue
+		}
+		if looked == maxRelatedFiles {
+			debug.Printf("Related history limited to %d files", maxRelatedFiles)
+			break
+		}
+		looked++
+
+		fileCommits, err := vcs.GetRecentCommits(root, commits, file)
+		if err != nil {
+			debug.Printf("Failed to get the history of %s: %v", file, err)
+			continue
+		}
+		if len(fileCommits) == 0 {
+			continue
+		}
+		entry := prompt.FileHistory{Path: file}
+		for _, commit := range fileCommits {
+			entry.Subjects = append(entry.Subjects, commit.Subject)
+		}
+		history = append(history, entry)
+	}
+	return history
+}
+
+// profileGitKey is the git config key holding the default prompt profile of a repository
+const profileGitKey = "gptcomet.profile"
+
+// resolveProfile returns the prompt profile to use: name if given, then the profile of the
+// repository set with "git config gptcomet.profile", then prompt.profile. It returns nil
+// when no profile is selected.
+func resolveProfile(cfgManager *config.Manager, vcs git.VCS, repoPath, name string) (*config.PromptProfile, error) {
+	if name == "" {
+		if gitVCS, ok := vcs.(*git.GitVCS); ok {
+			value, err := gitVCS.GetConfigValue(repoPath, profileGitKey)
+			if err != nil {
+				debug.Printf("Failed to read %s: %v", profileGitKey, err)
+			}
+			name = value
+		}
+	}
+	if name == "" {
+		value, _ := cfgManager.Get("prompt.profile")
+		name, _ = value.(string)
+	}
+	if name == "" {
+		return nil, nil
+	}
+	debug.Printf("Using prompt profile %s", name)
+	return cfgManager.GetProfile(name)
+}
+
+// outputLanguage returns the human readable name of the configured output.lang
+func outputLanguage(cfgManager *config.Manager) (string, error) {
+	langValue, ok := cfgManager.Get(LANGUAGE_KEY)
+	if !ok {
+		return "English", nil
+	}
+	lang, ok := langValue.(string)
+	if !ok {
+		return "", fmt.Errorf("output.lang is not a string: %v", langValue)
+	}
+	if name, ok := config.OutputLanguageMap[lang]; ok {
+		return name, nil
+	}
+	return lang, nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	text, err := renderPrompt("Diff:\n{{ placeholder }}", promptVars{"placeholder": "+a"})
+	require.NoError(t, err)
+	assert.Equal(t, "Diff:\n+a", text)
+
+	_, err = renderPrompt("{{ branch }}", promptVars{"diff": "+a"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errInvalidPrompt)
+	assert.Contains(t, err.Error(), `"branch"`)
+}
+
+func TestCommitPromptVars(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, "output:\n  lang: fr\n  rich_template: \"<title>\"\n")
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	vcs, dir, cleanupRepo := setupTestRepo(t, git.Git)
+	defer cleanupRepo()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "a.txt"))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "commit", "-m", "feat: add a"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "b.txt"))
+
+	text, err := renderPrompt(
+		"{{ repo }} {{ lang }} {{ output.rich_template }} {{ hint }}\n{{ staged_files }}\n{{ range .recent_commits }}- {{ . }}{{ end }}",
+		commitPromptVars(cfgManager, vcs, dir, "+b", "fixes #1"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(dir)+" French <title> fixes #1\nb.txt\n- feat: add a", text)
+
+	// Without a git repository the repository variables are empty
+	vars := commitPromptVars(cfgManager, nil, "", "+b", "")
+	assert.Equal(t, "+b", vars["diff"])
+	assert.Empty(t, vars["branch"])
+}
+
+func TestCommitTypesPrompt(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, `
+commit_types:
+  - name: sec
+    description: security fixes
+  - release
+`)
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	for _, rich := range []bool{false, true} {
+		text, err := renderPrompt(cfgManager.GetPrompt(nil, rich), commitPromptVars(cfgManager, nil, "", "+b", ""))
+		require.NoError(t, err)
+		assert.Contains(t, text, "use one of the following labels for the title:\n\n- sec: security fixes\n- release\n\nThe commit message template")
+		assert.NotContains(t, text, "- feat:")
+	}
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	cmd := &cobra.Command{Use: "remove"}
+	root.AddCommand(cmd)
+	keys, _ := completeConfigRemove(cmd, nil, "")
+	assert.Contains(t, keys, config.CommitTypesKey)
+	names, _ := completeConfigRemove(cmd, []string{config.CommitTypesKey}, "")
+	assert.Equal(t, []string{"sec", "release"}, names)
+}
+
+func TestOutputStylePrompt(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, "output:\n  style: gitmoji\n")
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	text, err := renderPrompt(cfgManager.GetPrompt(nil, false), commitPromptVars(cfgManager, nil, "", "+b", ""))
+	require.NoError(t, err)
+	assert.Contains(t, text, "start the title with the gitmoji of one of the following labels")
+	assert.Contains(t, text, "- :sparkles: feat: a commit of the type feat")
+
+	require.NoError(t, cfgManager.Set("output.style", config.OutputPlain))
+	text, err = renderPrompt(cfgManager.GetPrompt(nil, true), commitPromptVars(cfgManager, nil, "", "+b", ""))
+	require.NoError(t, err)
+	assert.Contains(t, text, "write the title as a plain imperative sentence")
+	assert.NotContains(t, text, "- feat:")
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	cmd := &cobra.Command{Use: "set"}
+	root.AddCommand(cmd)
+	styles, _ := completeConfigSet(cmd, []string{"output.style"}, "")
+	assert.Equal(t, config.OutputStyles, styles)
+}
+
+func TestResolveProfile(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, `
+prompt:
+  profile: terse
+  profiles:
+    terse:
+      brief_commit_message: "terse"
+    kernel:
+      brief_commit_message: "kernel"
+`)
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	vcs, dir, cleanupRepo := setupTestRepo(t, git.Git)
+	defer cleanupRepo()
+
+	profile, err := resolveProfile(cfgManager, vcs, dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "terse", profile.Name)
+
+	// The repository default wins over prompt.profile
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "config", profileGitKey, "kernel"))
+	profile, err = resolveProfile(cfgManager, vcs, dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "kernel", profile.Name)
+
+	// And the flag wins over both
+	profile, err = resolveProfile(cfgManager, vcs, dir, "terse")
+	require.NoError(t, err)
+	assert.Equal(t, "terse", profile.Name)
+
+	_, err = resolveProfile(cfgManager, vcs, dir, "missing")
+	assert.Error(t, err)
+
+	require.NoError(t, cfgManager.Set("prompt.profile", ""))
+	profile, err = resolveProfile(cfgManager, nil, "", "")
+	require.NoError(t, err)
+	assert.Nil(t, profile)
+}
+
+func TestRelatedHistory(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, "related_history:\n  enabled: true\n  commits: 2\nfile_ignore:\n  - go.sum\n")
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	_, dir, cleanupRepo := setupTestRepo(t, git.Git)
+	defer cleanupRepo()
+	vcs := &git.GitVCS{}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "pkg"), 0755))
+	for i, message := range []string{"feat(pkg): add a", "fix(pkg): handle b", "docs: note c", "fix(pkg): handle d"} {
+		name := filepath.Join("pkg", "a.go")
+		if i == 2 {
+			name = "README"
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(message), 0644))
+		require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "."))
+		require.NoError(t, testutils.RunCommand(t, dir, "git", "commit", "-m", message))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), []byte("new"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "."))
+
+	// Paths are relative to the root, even from a subdirectory
+	vars := commitPromptVars(cfgManager, vcs, filepath.Join(dir, "pkg"), "+new", "")
+	assert.Equal(t, "pkg/a.go:\n- fix(pkg): handle d\n- fix(pkg): handle b", fmt.Sprint(vars["related_history"]))
+
+	text, err := renderPrompt(cfgManager.GetPrompt(nil, false), vars)
+	require.NoError(t, err)
+	assert.Contains(t, text, "reuse their scope names and terminology:\npkg/a.go:\n- fix(pkg): handle d")
+}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/belingud/go-gptcomet/internal/client"
 	"github.com/belingud/go-gptcomet/internal/config"
 	"github.com/belingud/go-gptcomet/internal/debug"
 	"github.com/belingud/go-gptcomet/internal/llm"
@@ -15,9 +19,257 @@ import (
 	"golang.org/x/term"
 )
 
+// NewProviderCmd creates the provider command group
 func NewProviderCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "newprovider",
+		Use:   "provider",
+		Short: "Manage providers",
+	}
+
+	cmd.AddCommand(
+		newProviderAddCmd(),
+		newProviderListCmd(),
+		newProviderUseCmd(),
+		newProviderShowCmd(),
+		newProviderRemoveCmd(),
+		newProviderTestCmd(),
+	)
+	return cmd
+}
+
+// NewNewProviderCmd creates the deprecated newprovider command, kept as an alias of provider add
+func NewNewProviderCmd() *cobra.Command {
+	cmd := newProviderAddCmd()
+	cmd.Use = "newprovider"
+	cmd.Deprecated = "use \"gptcomet provider add\" instead"
+	return cmd
+}
+
+// newProviderManager loads the config manager for the provider subcommands
+func newProviderManager(cmd *cobra.Command) (*config.Manager, error) {
+	// Get config path from root command
+	configPath, err := cmd.Root().PersistentFlags().GetString("config")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+
+	// Create config manager
+	cfgManager, err := config.New(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+	return cfgManager, nil
+}
+
+// activeProvider returns the name of the active provider, or an empty string
+func activeProvider(cfgManager *config.Manager) string {
+	value, _ := cfgManager.Get("provider")
+	provider, _ := value.(string)
+	return provider
+}
+
+// providerSection returns the config section of provider, if it is configured
+func providerSection(cfgManager *config.Manager, provider string) (map[string]interface{}, bool) {
+	value, ok := cfgManager.Get(provider)
+	if !ok {
+		return nil, false
+	}
+	section, ok := value.(map[string]interface{})
+	return section, ok
+}
+
+// completeConfiguredProviders completes the names of the configured providers
+func completeConfiguredProviders(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfgManager, err := newProviderManager(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return configuredProviders(cfgManager), cobra.ShellCompDirectiveNoFileComp
+}
+
+// newProviderListCmd creates the provider list command
+func newProviderListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the supported providers and show which are configured",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgManager, err := newProviderManager(cmd)
+			if err != nil {
+				return err
+			}
+
+			active := activeProvider(cfgManager)
+			out := cmd.OutOrStdout()
+			for _, name := range llm.GetProviders() {
+				_, configured := providerSection(cfgManager, name)
+				switch {
+				case name == active:
+					fmt.Fprintf(out, "* %-12s %s\n", name, successStyle.Render("active"))
+				case configured:
+					fmt.Fprintf(out, "  %-12s configured\n", name)
+				default:
+					fmt.Fprintf(out, "  %s\n", name)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// newProviderUseCmd creates the provider use command
+func newProviderUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "use <name>",
+		Short:             "Switch the active provider",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfiguredProviders,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgManager, err := newProviderManager(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			if _, ok := providerSection(cfgManager, name); !ok {
+				return fmt.Errorf("provider %s is not configured, run `gptcomet provider add` first", name)
+			}
+			if err := cfgManager.Set("provider", name); err != nil {
+				return fmt.Errorf("failed to set provider: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Switched to provider %s\n", name)
+			return nil
+		},
+	}
+}
+
+// newProviderShowCmd creates the provider show command
+func newProviderShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "show <name>",
+		Short:             "Show the configuration of a provider with masked keys",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfiguredProviders,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgManager, err := newProviderManager(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			section, ok := providerSection(cfgManager, name)
+			if !ok {
+				return fmt.Errorf("provider %s is not configured", name)
+			}
+
+			masked := make(map[string]interface{}, len(section))
+			for k, v := range section {
+				masked[k] = v
+			}
+			config.MaskConfigAPIKeys(masked)
+
+			data, err := json.MarshalIndent(masked, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\n", string(data))
+			return nil
+		},
+	}
+}
+
+// newProviderRemoveCmd creates the provider remove command
+func newProviderRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "remove <name>",
+		Short:             "Remove the configuration of a provider",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfiguredProviders,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgManager, err := newProviderManager(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			if _, ok := providerSection(cfgManager, name); !ok {
+				return fmt.Errorf("provider %s is not configured", name)
+			}
+			if name == activeProvider(cfgManager) {
+				return fmt.Errorf("provider %s is active, switch to another provider with `gptcomet provider use` first", name)
+			}
+			if err := cfgManager.Remove(name, ""); err != nil {
+				return fmt.Errorf("failed to remove provider: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed provider %s\n", name)
+			return nil
+		},
+	}
+}
+
+// newProviderTestCmd creates the provider test command
+func newProviderTestCmd() *cobra.Command {
+	var timeout int
+
+	cmd := &cobra.Command{
+		Use:               "test [name]",
+		Short:             "Send a tiny prompt to a provider and report latency and usage",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeConfiguredProviders,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgManager, err := newProviderManager(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := activeProvider(cfgManager)
+			if len(args) == 1 {
+				name = args[0]
+			}
+			if name == "" {
+				return fmt.Errorf("provider not set")
+			}
+
+			clientConfig, err := cfgManager.GetProviderClientConfig(name)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+			defer cancel()
+
+			start := time.Now()
+			resp, err := client.New(clientConfig).Chat(ctx, "Reply with OK.", nil)
+			if err != nil {
+				return fmt.Errorf("provider %s failed: %w", name, err)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s %s/%s answered in %s: %s\n",
+				successStyle.Render("✔"), name, clientConfig.Model,
+				time.Since(start).Round(time.Millisecond), strings.TrimSpace(resp.Content))
+			if resp.Usage != nil {
+				fmt.Fprintf(out, "Token usage> prompt: %d, completion: %d, total: %d\n",
+					resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
+			} else {
+				fmt.Fprintln(out, "Token usage> not reported by the provider")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&timeout, "timeout", 30, "Timeout in seconds for the request")
+
+	return cmd
+}
+
+// newProviderAddCmd creates the provider add command, which configures a provider interactively
+func newProviderAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
 		Short: "Configure a new provider interactively",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// get providers list
@@ -56,8 +308,21 @@ func NewProviderCmd() *cobra.Command {
 			requiredConfig := provider.GetRequiredConfig()
 			debug.Printf("Required config: %v", requiredConfig)
 
-			// Create and run config input
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			// Create and run config input, offering the models listed by `gptcomet models`
 			configInput := ui.NewConfigInput(requiredConfig)
+			configInput.SetSuggestions("model", cachedModels(cfgManager, providerName))
 			p = tea.NewProgram(configInput)
 			m, err = p.Run()
 			if err != nil {
@@ -73,18 +338,6 @@ func NewProviderCmd() *cobra.Command {
 			configs := model2.GetConfigs()
 			debug.Printf("Config values: %v", configs)
 
-			// Get config path from root command
-			configPath, err := cmd.Root().PersistentFlags().GetString("config")
-			if err != nil {
-				return fmt.Errorf("failed to get config path: %w", err)
-			}
-
-			// Create config manager
-			cfgManager, err := config.New(configPath)
-			if err != nil {
-				return fmt.Errorf("failed to create config manager: %w", err)
-			}
-
 			// Check if provider config already exists
 			existingConfig, _ := cfgManager.Get(providerName)
 			if existingConfig != nil {
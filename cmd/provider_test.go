@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/belingud/go-gptcomet/internal/config"
 	"github.com/belingud/go-gptcomet/internal/llm"
+	"github.com/belingud/go-gptcomet/internal/testutils"
 	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,13 +20,18 @@ func TestNewProviderCmd(t *testing.T) {
 	require.NotNil(t, cmd)
 
 	// Test command basic properties
-	assert.Equal(t, "newprovider", cmd.Use)
-	assert.Equal(t, "Configure a new provider interactively", cmd.Short)
-	assert.NotNil(t, cmd.RunE)
+	assert.Equal(t, "provider", cmd.Use)
+	assert.Equal(t, "Manage providers", cmd.Short)
+	for _, name := range []string{"add", "list", "use", "show", "remove", "test"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		assert.Equal(t, name, sub.Name())
+	}
 
 	// Create a buffer to capture output
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"add"})
 
 	// Set test environment
 	t.Setenv("GPTCOMET_TEST", "1")
@@ -45,6 +55,7 @@ func TestProviderCmd_NonInteractive(t *testing.T) {
 	cmd := NewProviderCmd()
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"add"})
 
 	// Set test environment
 	t.Setenv("GPTCOMET_TEST", "1")
@@ -68,6 +79,7 @@ func TestProviderCmd_EmptyProvidersList(t *testing.T) {
 	cmd := NewProviderCmd()
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"add"})
 
 	// Set test environment
 	t.Setenv("GPTCOMET_TEST", "1")
@@ -88,6 +100,7 @@ func TestProviderCmd_MultipleProviders(t *testing.T) {
 	cmd := NewProviderCmd()
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"add"})
 
 	// Set test environment
 	t.Setenv("GPTCOMET_TEST", "1")
@@ -110,3 +123,93 @@ func TestProviderCmd_MultipleProviders(t *testing.T) {
 	assert.Contains(t, output, "test-provider1")
 	assert.Contains(t, output, "test-provider2")
 }
+
+func TestNewNewProviderCmd(t *testing.T) {
+	cmd := NewNewProviderCmd()
+	assert.Equal(t, "newprovider", cmd.Use)
+	assert.NotEmpty(t, cmd.Deprecated)
+	assert.NotNil(t, cmd.RunE)
+}
+
+// runProviderCmd runs the provider command group with args against configPath
+func runProviderCmd(t *testing.T, configPath string, args ...string) (string, error) {
+	t.Helper()
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	root.AddCommand(NewProviderCmd())
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs(append([]string{"provider"}, args...))
+	err := root.Execute()
+	return buf.String(), err
+}
+
+func TestProviderSubcommands(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-abcdefghijkl
+  model: gpt-4o
+ollama:
+  api_key: ollama-key-123
+  model: llama3
+`)
+	defer cleanup()
+
+	output, err := runProviderCmd(t, configPath, "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "* openai")
+	assert.Regexp(t, `ollama\s+configured`, output)
+	assert.Contains(t, output, "  claude\n")
+
+	output, err = runProviderCmd(t, configPath, "show", "ollama")
+	require.NoError(t, err)
+	assert.Contains(t, output, `"model": "llama3"`)
+	assert.NotContains(t, output, "ollama-key-123")
+
+	_, err = runProviderCmd(t, configPath, "use", "claude")
+	assert.Error(t, err)
+	_, err = runProviderCmd(t, configPath, "use", "ollama")
+	require.NoError(t, err)
+
+	_, err = runProviderCmd(t, configPath, "remove", "ollama")
+	assert.Error(t, err, "the active provider cannot be removed")
+	_, err = runProviderCmd(t, configPath, "remove", "openai")
+	require.NoError(t, err)
+
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ollama", activeProvider(cfgManager))
+	_, ok := providerSection(cfgManager, "openai")
+	assert.False(t, ok)
+	// show must not mask the stored key
+	section, ok := providerSection(cfgManager, "ollama")
+	require.True(t, ok)
+	assert.Equal(t, "ollama-key-123", section["api_key"])
+}
+
+func TestProviderTestCmd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"OK"}}],"usage":{"prompt_tokens":9,"completion_tokens":1,"total_tokens":10}}`))
+	}))
+	defer server.Close()
+
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: `+server.URL+`
+  model: gpt-4o
+`)
+	defer cleanup()
+
+	output, err := runProviderCmd(t, configPath, "test")
+	require.NoError(t, err)
+	assert.Contains(t, output, "openai/gpt-4o answered in")
+	assert.Contains(t, output, "prompt: 9, completion: 1, total: 10")
+
+	_, err = runProviderCmd(t, configPath, "test", "claude")
+	assert.Error(t, err)
+}
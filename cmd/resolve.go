@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+// stripCodeFence removes a surrounding markdown code fence, including its language tag
+func stripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return text
+	}
+	trimmed = strings.TrimSuffix(trimmed, "```")
+	if idx := strings.Index(trimmed, "\n"); idx >= 0 {
+		return strings.Trim(trimmed[idx+1:], "\n")
+	}
+	return ""
+}
+
+// formatSideCommits lists commits as "hash subject" lines for the resolve prompt
+func formatSideCommits(commits []git.Commit) string {
+	if len(commits) == 0 {
+		return "(unknown)"
+	}
+	var sb strings.Builder
+	for _, c := range commits {
+		fmt.Fprintf(&sb, "- %s %s\n", c.ShortHash(), c.Subject)
+		if c.Body != "" {
+			sb.WriteString("  " + strings.ReplaceAll(c.Body, "\n", "\n  ") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// conflictedFiles returns the directory the conflicted files are relative to and the files
+// to resolve, either args given relative to cwd or every conflicted file. Git lists
+// conflicts relative to the repository root, so args are made relative to it as well.
+func conflictedFiles(vcs git.VCS, cwd string, args []string) (string, []string, error) {
+	gitVCS, ok := vcs.(*git.GitVCS)
+	if !ok {
+		if len(args) > 0 {
+			return cwd, args, nil
+		}
+		files, err := vcs.GetConflictedFiles(cwd)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to list conflicted files: %w", err)
+		}
+		return cwd, files, nil
+	}
+
+	root, err := gitVCS.GetRepoRoot(cwd)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+	if len(args) == 0 {
+		files, err := gitVCS.GetConflictedFiles(root)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to list conflicted files: %w", err)
+		}
+		return root, files, nil
+	}
+
+	// Resolve symlinks so that cwd and root share the same prefix, e.g. /tmp on macOS
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	files := make([]string, len(args))
+	for i, arg := range args {
+		if !filepath.IsAbs(arg) {
+			arg = filepath.Join(cwd, arg)
+		}
+		rel, err := filepath.Rel(root, arg)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", nil, fmt.Errorf("%s is outside of the repository %s", args[i], root)
+		}
+		files[i] = filepath.ToSlash(rel)
+	}
+	return root, files, nil
+}
+
+// writeResolved replaces the content of the conflicted file at path, keeping its
+// permissions, e.g. the executable bit of a script
+func writeResolved(path string, content string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), info.Mode().Perm())
+}
+
+// NewResolveCmd creates a new resolve command
+func NewResolveCmd() *cobra.Command {
+	var (
+		useSVN bool
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "resolve [path]",
+		Short: "Propose resolutions for merge conflicts",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+
+			// Create VCS instance based on flag
+			vcsType := git.Git
+			if useSVN {
+				vcsType = git.SVN
+			}
+			vcs, err := git.NewVCS(vcsType)
+			if err != nil {
+				return fmt.Errorf("failed to create VCS (%s): %w", vcsType, err)
+			}
+
+			repoPath, files, err := conflictedFiles(vcs, cwd, args)
+			if err != nil {
+				return err
+			}
+			debug.Printf("Using repository path: %s", repoPath)
+			if len(files) == 0 {
+				fmt.Println("No conflicted files found")
+				return nil
+			}
+			debug.Printf("Conflicted files: %v", files)
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			// Get client config
+			clientConfig, err := cfgManager.GetClientConfig()
+			if err != nil {
+				return err
+			}
+			client := client.New(clientConfig)
+			prompt := cfgManager.GetNamedPrompt("resolve")
+
+			reader := bufio.NewReader(os.Stdin)
+			for _, file := range files {
+				content, err := os.ReadFile(filepath.Join(repoPath, file))
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", file, err)
+				}
+				conflictFile, err := git.ParseConflictFile(file, string(content))
+				if err != nil {
+					return err
+				}
+				if len(conflictFile.Conflicts) == 0 {
+					fmt.Printf("No conflict markers found in %s\n", file)
+					continue
+				}
+
+				var oursCommits, theirsCommits []git.Commit
+				if gitVCS, ok := vcs.(*git.GitVCS); ok {
+					oursCommits, theirsCommits, err = gitVCS.GetConflictSideCommits(repoPath, file, 5)
+					if err != nil {
+						debug.Printf("Failed to get commits of both sides: %v", err)
+					}
+				}
+
+				for i, conflict := range conflictFile.Conflicts {
+					base := "(not available, set merge.conflictStyle to diff3 to include it)\n"
+					if conflict.HasBase {
+						base = conflict.Base
+					}
+					conflictPrompt, err := renderPrompt(prompt, promptVars{
+						"file":           file,
+						"ours_label":     conflict.OursLabel,
+						"theirs_label":   conflict.TheirsLabel,
+						"ours_commits":   formatSideCommits(oursCommits),
+						"theirs_commits": formatSideCommits(theirsCommits),
+						"ours":           conflict.Ours,
+						"base":           base,
+						"theirs":         conflict.Theirs,
+					})
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("\nConflict %d/%d in %s\n", i+1, len(conflictFile.Conflicts), file)
+					var proposal string
+				conflictLoop:
+					for {
+						if proposal == "" {
+							fmt.Println("🤖 Hang tight, I'm working out a resolution!")
+							resp, err := client.Chat(context.Background(), conflictPrompt, nil)
+							if err != nil {
+								return fmt.Errorf("failed to resolve conflict: %w", err)
+							}
+							proposal = stripCodeFence(resp.Content)
+						}
+						fmt.Printf("\nProposed resolution:\n%s\n", boxStyle.Render(proposal))
+
+						if dryRun {
+							break
+						}
+
+						fmt.Print("\nApply this resolution? ([A]ccept/[e]dit/[r]etry/[s]kip): ")
+						answer, err := reader.ReadString('\n')
+						if err != nil {
+							return fmt.Errorf("failed to read answer: %w", err)
+						}
+						switch strings.ToLower(strings.TrimSpace(answer)) {
+						case "", "a", "accept":
+							conflict.Resolve(proposal)
+							break conflictLoop
+						case "e", "edit":
+							edited, err := editText(proposal)
+							if err != nil {
+								fmt.Printf("Error editing resolution: %v\n", err)
+								continue
+							}
+							proposal = edited
+						case "r", "retry":
+							proposal = ""
+						case "s", "skip":
+							break conflictLoop
+						default:
+							fmt.Println("Invalid option, please try again")
+						}
+					}
+				}
+
+				if dryRun {
+					continue
+				}
+
+				if err := writeResolved(filepath.Join(repoPath, file), conflictFile.Render()); err != nil {
+					return fmt.Errorf("failed to write %s: %w", file, err)
+				}
+				if !conflictFile.Resolved() {
+					fmt.Printf("%s still has unresolved conflicts, not staging it\n", file)
+					continue
+				}
+				if err := vcs.StageFiles(repoPath, []string{file}); err != nil {
+					return fmt.Errorf("failed to stage %s: %w", file, err)
+				}
+				fmt.Println(successStyle.Render(fmt.Sprintf("Resolved and staged %s", file)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&useSVN, "svn", false, "Use SVN instead of Git")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the proposed resolutions without writing files")
+
+	return cmd
+}
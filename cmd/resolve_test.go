@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResolveCmd(t *testing.T) {
+	cmd := NewResolveCmd()
+	require.NotNil(t, cmd)
+
+	assert.NotNil(t, cmd.Flags().Lookup("svn"))
+	assert.NotNil(t, cmd.Flags().Lookup("dry-run"))
+	assert.Error(t, cmd.Args(cmd, []string{"a", "b"}))
+}
+
+func TestStripCodeFence(t *testing.T) {
+	assert.Equal(t, "x := 1", stripCodeFence("```go\nx := 1\n```"))
+	assert.Equal(t, "x := 1", stripCodeFence("```\nx := 1\n```\n"))
+	assert.Equal(t, "x := 1\n", stripCodeFence("x := 1\n"))
+}
+
+func TestFormatSideCommits(t *testing.T) {
+	assert.Equal(t, "(unknown)", formatSideCommits(nil))
+	out := formatSideCommits([]git.Commit{{Hash: "abcdef123", Subject: "fix: x", Body: "why"}})
+	assert.Equal(t, "- abcdef1 fix: x\n  why\n", out)
+}
+
+func TestConflictedFiles_Subdirectory(t *testing.T) {
+	vcs, dir, cleanup := setupTestRepo(t, git.Git)
+	defer cleanup()
+
+	sub := filepath.Join(dir, "pkg")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	file := filepath.Join(sub, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("base\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "."))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "commit", "-m", "base"))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "checkout", "-b", "feature"))
+	require.NoError(t, os.WriteFile(file, []byte("theirs\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "commit", "-am", "feat: their change"))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "checkout", "-"))
+	require.NoError(t, os.WriteFile(file, []byte("ours\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "commit", "-am", "fix: our change"))
+	// The merge is expected to stop on a conflict
+	assert.Error(t, testutils.RunCommand(t, dir, "git", "merge", "feature"))
+
+	// Listed conflicts are relative to the root, not to the subdirectory
+	repoPath, files, err := conflictedFiles(vcs, sub, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pkg/main.go"}, files)
+	_, err = os.Stat(filepath.Join(repoPath, files[0]))
+	assert.NoError(t, err)
+
+	// Paths given as arguments are relative to the subdirectory
+	repoPath, files, err = conflictedFiles(vcs, sub, []string{"main.go"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pkg/main.go"}, files)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, files[0]), []byte("resolved\n"), 0644))
+	require.NoError(t, vcs.StageFiles(repoPath, files))
+	remaining, err := vcs.GetConflictedFiles(repoPath)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	_, _, err = conflictedFiles(vcs, sub, []string{"../../outside.go"})
+	assert.Error(t, err)
+}
+
+func TestWriteResolved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build.sh")
+	require.NoError(t, os.WriteFile(path, []byte("<<<<<<< HEAD\n"), 0755))
+
+	require.NoError(t, writeResolved(path, "#!/bin/sh\nmake\n"))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nmake\n", string(content))
+
+	assert.Error(t, writeResolved(filepath.Join(t.TempDir(), "missing.go"), ""))
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/llm"
+	"github.com/belingud/go-gptcomet/internal/rpc"
+	"github.com/belingud/go-gptcomet/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// rpcOptions are the options shared by the generation methods
+type rpcOptions struct {
+	// Provider answers the request instead of the active provider
+	Provider string `json:"provider"`
+	// Rich selects the rich commit message prompt
+	Rich bool `json:"rich"`
+	// Lang is the output language code, output.lang by default
+	Lang string `json:"lang"`
+	// Diff is used instead of the staged changes of the repository
+	Diff string `json:"diff"`
+	// Hint is extra context for the commit message
+	Hint string `json:"hint"`
+}
+
+// rpcParams are the parameters of the generation methods
+type rpcParams struct {
+	RepoPath string     `json:"repoPath"`
+	Message  string     `json:"message"`
+	Code     string     `json:"code"`
+	Language string     `json:"language"`
+	Options  rpcOptions `json:"options"`
+}
+
+// rpcProvider is a configured provider returned by listProviders
+type rpcProvider struct {
+	Name   string `json:"name"`
+	Model  string `json:"model"`
+	Active bool   `json:"active"`
+}
+
+// rpcProgress is the payload of $/progress notifications
+type rpcProgress struct {
+	ID      json.RawMessage `json:"id"`
+	Message string          `json:"message"`
+}
+
+// rpcHandler dispatches JSON-RPC requests to the serve endpoints
+type rpcHandler struct {
+	cfgManager *config.Manager
+	conn       *rpc.Conn
+
+	mu       sync.Mutex
+	servers  map[string]*server
+	cancels  map[string]context.CancelFunc
+	inFlight sync.WaitGroup
+}
+
+// newRPCHandler creates a handler answering the requests read from conn
+func newRPCHandler(cfgManager *config.Manager, conn *rpc.Conn) *rpcHandler {
+	return &rpcHandler{
+		cfgManager: cfgManager,
+		conn:       conn,
+		servers:    make(map[string]*server),
+		cancels:    make(map[string]context.CancelFunc),
+	}
+}
+
+// server returns the endpoints answering with provider, the active provider if empty
+func (h *rpcHandler) server(provider string) (*server, error) {
+	if provider == "" {
+		provider = activeProvider(h.cfgManager)
+		if provider == "" {
+			return nil, &badRequestError{msg: "provider not set"}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.servers[provider]; ok {
+		return s, nil
+	}
+	clientConfig, err := h.cfgManager.GetProviderClientConfig(provider)
+	if err != nil {
+		return nil, &badRequestError{msg: err.Error()}
+	}
+	s := &server{cfgManager: h.cfgManager, client: client.New(clientConfig)}
+	h.servers[provider] = s
+	return s, nil
+}
+
+// listProviders returns the configured providers
+func (h *rpcHandler) listProviders() []rpcProvider {
+	active := activeProvider(h.cfgManager)
+	providers := []rpcProvider{}
+	for _, name := range configuredProviders(h.cfgManager) {
+		section, _ := providerSection(h.cfgManager, name)
+		model, _ := section["model"].(string)
+		providers = append(providers, rpcProvider{Name: name, Model: model, Active: name == active})
+	}
+	return providers
+}
+
+// rpcError converts an endpoint error to a JSON-RPC error
+func rpcError(err error) *rpc.Error {
+	var badRequest *badRequestError
+	var limitErr *client.LimitError
+	switch {
+	case errors.As(err, &badRequest):
+		return rpc.Errorf(rpc.CodeInvalidParams, "%v", err)
+	case errors.As(err, &limitErr):
+		return rpc.Errorf(rpc.CodeLimitExceeded, "%v", err)
+	case errors.Is(err, errInvalidPrompt):
+		return rpc.Errorf(rpc.CodeInternalError, "%v", err)
+	case errors.Is(err, context.Canceled):
+		return rpc.Errorf(rpc.CodeRequestCancelled, "request cancelled")
+	default:
+		return rpc.Errorf(rpc.CodeRequestFailed, "%v", err)
+	}
+}
+
+// rpcEndpoints are the generation methods and the endpoints answering them
+var rpcEndpoints = map[string]func(s *server, ctx context.Context, req serveRequest) (*types.CompletionResponse, error){
+	"generateCommitMessage": (*server).commitMessage,
+	"translate":             (*server).translate,
+	"review":                (*server).review,
+	"explain":               (*server).explain,
+}
+
+// generate answers a generation request with endpoint in the background, reporting its progress
+func (h *rpcHandler) generate(ctx context.Context, req *rpc.Request, endpoint func(*server, context.Context, serveRequest) (*types.CompletionResponse, error)) {
+	var params rpcParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			h.reply(req.ID, nil, rpc.Errorf(rpc.CodeInvalidParams, "invalid params: %v", err))
+			return
+		}
+	}
+	s, err := h.server(params.Options.Provider)
+	if err != nil {
+		h.reply(req.ID, nil, rpcError(err))
+		return
+	}
+
+	key := string(req.ID)
+	ctx, cancel := context.WithCancel(ctx)
+	h.mu.Lock()
+	h.cancels[key] = cancel
+	h.mu.Unlock()
+
+	h.inFlight.Add(1)
+	go func() {
+		defer h.inFlight.Done()
+		defer func() {
+			h.mu.Lock()
+			delete(h.cancels, key)
+			h.mu.Unlock()
+			cancel()
+		}()
+
+		ctx := withProgress(ctx, func(message string) {
+			if err := h.conn.Notify("$/progress", rpcProgress{ID: req.ID, Message: message}); err != nil {
+				debug.Printf("Failed to send progress: %v", err)
+			}
+		})
+		var warnings []string
+		ctx = withWarnings(ctx, func(warning string) {
+			warnings = append(warnings, warning)
+		})
+		resp, err := endpoint(s, ctx, serveRequest{
+			Diff:     params.Options.Diff,
+			Repo:     params.RepoPath,
+			Rich:     params.Options.Rich,
+			Message:  params.Message,
+			Code:     params.Code,
+			Language: params.Language,
+			Lang:     params.Options.Lang,
+			Hint:     params.Options.Hint,
+		})
+		if err != nil {
+			debug.Printf("%s failed: %v", req.Method, err)
+			h.reply(req.ID, nil, rpcError(err))
+			return
+		}
+		h.reply(req.ID, serveResponse{Content: strings.TrimSpace(resp.Content), Usage: resp.Usage, Warnings: warnings}, nil)
+	}()
+}
+
+// reply sends the response of the request with id, logging write failures
+func (h *rpcHandler) reply(id json.RawMessage, result interface{}, err *rpc.Error) {
+	if writeErr := h.conn.Reply(id, result, err); writeErr != nil {
+		debug.Printf("Failed to send response: %v", writeErr)
+	}
+}
+
+// cancel cancels the in-flight request whose ID is in params
+func (h *rpcHandler) cancel(params json.RawMessage) {
+	var p struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if cancel, ok := h.cancels[string(p.ID)]; ok {
+		cancel()
+	}
+}
+
+// run answers requests until the input is closed or an exit notification is received
+func (h *rpcHandler) run(ctx context.Context) error {
+	ctx, cancelAll := context.WithCancel(ctx)
+	defer func() {
+		cancelAll()
+		h.inFlight.Wait()
+	}()
+
+	shutdown := false
+	for {
+		body, err := h.conn.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req rpc.Request
+		if err := json.Unmarshal(body, &req); err != nil {
+			h.reply(json.RawMessage("null"), nil, rpc.Errorf(rpc.CodeParseError, "invalid message: %v", err))
+			continue
+		}
+		debug.Printf("Received %s", req.Method)
+
+		switch {
+		case req.Method == "exit":
+			return nil
+		case req.Method == "$/cancelRequest":
+			h.cancel(req.Params)
+		case req.IsNotification():
+			debug.Printf("Ignoring notification %s", req.Method)
+		case req.JSONRPC != rpc.Version:
+			h.reply(req.ID, nil, rpc.Errorf(rpc.CodeInvalidRequest, "unsupported jsonrpc version %q", req.JSONRPC))
+		case shutdown:
+			h.reply(req.ID, nil, rpc.Errorf(rpc.CodeInvalidRequest, "server is shutting down"))
+		case req.Method == "shutdown":
+			h.inFlight.Wait()
+			shutdown = true
+			h.reply(req.ID, nil, nil)
+		case req.Method == "listProviders":
+			h.reply(req.ID, h.listProviders(), nil)
+		case rpcEndpoints[req.Method] != nil:
+			h.generate(ctx, &req, rpcEndpoints[req.Method])
+		default:
+			h.reply(req.ID, nil, rpc.Errorf(rpc.CodeMethodNotFound, "method not found: %s", req.Method))
+		}
+	}
+}
+
+// NewRPCCmd creates a new rpc command
+func NewRPCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rpc",
+		Short: "Speak JSON-RPC over stdin and stdout for editor plugins",
+		Long: `Answer JSON-RPC 2.0 requests on stdin and stdout, framed with LSP style
+"Content-Length" headers, so editor plugins can run gptcomet as a child process.
+
+Methods:
+
+  generateCommitMessage  {"repoPath", "options": {"provider", "rich", "lang", "diff", "hint"}}
+  translate              {"message", "options": {"provider", "lang"}}
+  review                 {"repoPath", "options": {"provider", "lang", "diff"}}
+  explain                {"code", "language", "options": {"provider", "lang"}}
+  listProviders          returns [{"name", "model", "active"}]
+  shutdown, exit         stop the server
+
+Generation methods return {"content", "usage", "warnings"} and send "$/progress"
+notifications {"id", "message"} while they run. "$/cancelRequest" {"id"} cancels a
+pending request. Commit messages are validated, translated, scoped and formatted like
+with the commit command, "warnings" lists the problems found by the validation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			// stdout carries the protocol only, send notices to stderr
+			config.SetOutput(cmd.ErrOrStderr())
+			llm.SetUsageOutput(cmd.ErrOrStderr())
+
+			// There is no terminal to confirm requests over a limit, refuse them instead
+			client.SetLimitConfirm(nil)
+
+			conn := rpc.NewConn(cmd.InOrStdin(), cmd.OutOrStdout())
+			return newRPCHandler(cfgManager, conn).run(context.Background())
+		},
+	}
+
+	return cmd
+}
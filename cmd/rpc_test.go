@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/rpc"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRPCCmd(t *testing.T) {
+	server := newMockCompletionServer(t)
+	defer server.Close()
+
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: `+server.URL+`
+  model: gpt-4o
+ollama:
+  api_base: http://localhost:11434/api
+  model: llama3
+output:
+  lang: en
+`)
+	defer cleanup()
+
+	var in bytes.Buffer
+	requests := rpc.NewConn(nil, &in)
+	send := func(msg string) {
+		require.NoError(t, requests.Write(json.RawMessage(msg)))
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"listProviders"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"generateCommitMessage","params":{"repoPath":".","options":{"diff":"diff --git a/x b/x"}}}`)
+	send(`{"jsonrpc":"2.0","id":3,"method":"explain","params":{}}`)
+	send(`{"jsonrpc":"2.0","id":4,"method":"commit"}`)
+	send(`{"jsonrpc":"2.0","method":"initialized"}`)
+	in.WriteString("Content-Length: 8\r\n\r\nnot json")
+	send(`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`)
+	send(`{"jsonrpc":"2.0","id":6,"method":"listProviders"}`)
+	send(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	root.AddCommand(NewRPCCmd())
+	var out bytes.Buffer
+	root.SetIn(&in)
+	root.SetOut(&out)
+	root.SetArgs([]string{"rpc"})
+	require.NoError(t, root.Execute())
+
+	type message struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+		Error  *rpc.Error      `json:"error"`
+	}
+	responses := make(map[string]message)
+	var progress []string
+	replies := rpc.NewConn(&out, io.Discard)
+	for {
+		body, err := replies.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		var msg message
+		require.NoError(t, json.Unmarshal(body, &msg))
+		if msg.Method == "$/progress" {
+			progress = append(progress, string(msg.Params))
+			continue
+		}
+		responses[string(msg.ID)] = msg
+	}
+
+	assert.JSONEq(t, `[{"name":"ollama","model":"llama3","active":false},{"name":"openai","model":"gpt-4o","active":true}]`,
+		string(responses["1"].Result))
+	assert.JSONEq(t, `{"content":"fix: handle nil config","usage":{"prompt_tokens":100,"completion_tokens":6,"total_tokens":106}}`,
+		string(responses["2"].Result))
+	assert.Equal(t, []string{`{"id":2,"message":"Generating commit message"}`}, progress)
+
+	require.NotNil(t, responses["3"].Error)
+	assert.Equal(t, rpc.CodeInvalidParams, responses["3"].Error.Code)
+	require.NotNil(t, responses["4"].Error)
+	assert.Equal(t, rpc.CodeMethodNotFound, responses["4"].Error.Code)
+	require.NotNil(t, responses["null"].Error)
+	assert.Equal(t, rpc.CodeParseError, responses["null"].Error.Code)
+	assert.JSONEq(t, `{}`, string(responses["5"].Result))
+	require.NotNil(t, responses["6"].Error)
+	assert.Equal(t, rpc.CodeInvalidRequest, responses["6"].Error.Code)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatSplitSuggestion suggests committing the files of each scope separately
+func formatSplitSuggestion(scopes []string, byScope map[string][]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The staged changes span the scopes %s, consider one commit per scope:\n", strings.Join(scopes, ", "))
+	for _, name := range scopes {
+		fmt.Fprintf(&b, "  %s: %s\n", name, strings.Join(byScope[name], " "))
+	}
+	return b.String()
+}
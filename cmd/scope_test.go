@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/commitmsg"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagedScopes(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, `
+scopes:
+  auto: true
+  map:
+    services/billing/**: billing
+`)
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	_, dir, cleanupRepo := setupTestRepo(t, git.Git)
+	defer cleanupRepo()
+	vcs := &git.GitVCS{}
+	for _, name := range []string{"go.mod", "services/billing/main.go", "web/app/package.json", "web/app/src/index.ts", "tools/lint/go.mod"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "."))
+
+	scopes, byScope, err := commitmsg.FileScopes(cfgManager, vcs, dir, []string{"services/billing/main.go", "web/app/src/index.ts", "go.mod"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "billing"}, scopes)
+	assert.Equal(t, "The staged changes span the scopes app, billing, consider one commit per scope:\n"+
+		"  app: web/app/src/index.ts\n"+
+		"  billing: services/billing/main.go\n", formatSplitSuggestion(scopes, byScope))
+
+	// The scopes are available to the commit prompts
+	vars := commitPromptVars(cfgManager, vcs, dir, "+x", "")
+	text, err := renderPrompt(cfgManager.GetPrompt(nil, false), vars)
+	require.NoError(t, err)
+	assert.Contains(t, text, "use (app,billing,lint) as the scope")
+
+	require.NoError(t, cfgManager.Set("scopes.auto", false))
+	scopes, _, err = commitmsg.FileScopes(cfgManager, vcs, dir, []string{"web/app/src/index.ts"})
+	require.NoError(t, err)
+	assert.Empty(t, scopes)
+
+	assert.Error(t, cfgManager.Set("scopes.multiple", "merge"))
+}
@@ -0,0 +1,591 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/commitmsg"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// serveTokenEnv is the environment variable holding the serve token when --token is not set
+const serveTokenEnv = "GPTCOMET_SERVE_TOKEN"
+
+// maxServeRequestSize is the maximum size of a request body
+const maxServeRequestSize = 10 << 20
+
+// serveRequest is the JSON body accepted by the serve endpoints
+type serveRequest struct {
+	// Diff is the diff to describe or review
+	Diff string `json:"diff"`
+	// Repo is a repository whose staged changes are used when Diff is empty
+	Repo string `json:"repo"`
+	// Rich selects the rich commit message prompt
+	Rich bool `json:"rich"`
+	// Message is the commit message to translate
+	Message string `json:"message"`
+	// Code is the code to explain, written in Language
+	Code     string `json:"code"`
+	Language string `json:"language"`
+	// Lang is the output language code, output.lang by default
+	Lang string `json:"lang"`
+	// Hint is extra context for the commit message
+	Hint string `json:"hint"`
+	// Stream sends the response as server-sent events
+	Stream bool `json:"stream"`
+}
+
+// serveResponse is the JSON body returned by the serve endpoints
+type serveResponse struct {
+	Content string       `json:"content"`
+	Usage   *types.Usage `json:"usage,omitempty"`
+	// Warnings are the problems found in the generated commit message
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// serveError is the JSON body returned on errors
+type serveError struct {
+	Error string `json:"error"`
+}
+
+// badRequestError is an invalid request, reported with status 400
+type badRequestError struct {
+	msg string
+}
+
+// Error returns the message of the error
+func (e *badRequestError) Error() string {
+	return e.msg
+}
+
+// progressKey is the context key of the progress reporter
+type progressKey struct{}
+
+// withProgress returns a context whose endpoint progress is reported to fn
+func withProgress(ctx context.Context, fn func(message string)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress reports the progress of an endpoint, if ctx has a progress reporter
+func reportProgress(ctx context.Context, message string) {
+	if fn, ok := ctx.Value(progressKey{}).(func(string)); ok {
+		fn(message)
+	}
+}
+
+// warningKey is the context key of the receiver of warnings
+type warningKey struct{}
+
+// withWarnings returns a context whose endpoint warnings are sent to fn
+func withWarnings(ctx context.Context, fn func(warning string)) context.Context {
+	return context.WithValue(ctx, warningKey{}, fn)
+}
+
+// reportWarning reports a warning of an endpoint, if ctx has a warning receiver
+func reportWarning(ctx context.Context, warning string) {
+	if fn, ok := ctx.Value(warningKey{}).(func(string)); ok {
+		fn(warning)
+	}
+}
+
+// deltaKey is the context key of the receiver of streamed answer chunks
+type deltaKey struct{}
+
+// withDeltas returns a context whose streamed answer chunks are sent to fn
+func withDeltas(ctx context.Context, fn func(delta string)) context.Context {
+	return context.WithValue(ctx, deltaKey{}, fn)
+}
+
+// server answers the serve endpoints with a single client
+type server struct {
+	cfgManager *config.Manager
+	client     *client.Client
+	token      string
+	// listen is the address the server listens on, requests must name it as Host
+	listen string
+}
+
+// newServeHandler returns the handler of the serve endpoints listening on listen.
+// When token is not empty every request must send it as a bearer token.
+func newServeHandler(cfgManager *config.Manager, c *client.Client, token, listen string) http.Handler {
+	s := &server{cfgManager: cfgManager, client: c, token: token, listen: listen}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/health", s.handleHealth)
+	mux.HandleFunc("/v1/commit-message", s.handle(s.commitMessage))
+	mux.HandleFunc("/v1/translate", s.handle(s.translate))
+	mux.HandleFunc("/v1/review", s.handle(s.review))
+	mux.HandleFunc("/v1/explain", s.handle(s.explain))
+	return s.authorize(mux)
+}
+
+// authorize rejects requests sent by web pages and requests without the expected bearer token.
+// Browsers send an Origin header with cross-origin requests, and a page resolving its
+// own domain to the loopback address (DNS rebinding) sends its domain as Host.
+func (s *server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeServeJSON(w, http.StatusForbidden, serveError{Error: "requests from web pages are not allowed"})
+			return
+		}
+		if !allowedHost(r.Host, s.listen) {
+			writeServeJSON(w, http.StatusForbidden, serveError{Error: fmt.Sprintf("unexpected Host %q", r.Host)})
+			return
+		}
+		if s.token != "" {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+				writeServeJSON(w, http.StatusUnauthorized, serveError{Error: "invalid or missing token"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether host, the Host header of a request, names the address
+// listen. Servers on a loopback address only accept loopback names, servers on every
+// interface accept any name with their port.
+func allowedHost(host, listen string) bool {
+	if strings.HasPrefix(listen, "unix:") {
+		return true
+	}
+	listenHost, listenPort, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, "80"
+	}
+	if port != listenPort {
+		return false
+	}
+	if strings.EqualFold(name, listenHost) || isLoopback(net.JoinHostPort(name, port)) {
+		return true
+	}
+	ip := net.ParseIP(listenHost)
+	return listenHost == "" || (ip != nil && ip.IsUnspecified())
+}
+
+// handleHealth reports the active provider
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	provider, _ := s.cfgManager.Get("provider")
+	writeServeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "provider": provider})
+}
+
+// handle decodes the request, runs endpoint and writes its response as JSON or server-sent events
+func (s *server) handle(endpoint func(ctx context.Context, req serveRequest) (*types.CompletionResponse, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeServeJSON(w, http.StatusMethodNotAllowed, serveError{Error: "method not allowed"})
+			return
+		}
+		// Browsers send text/plain and form bodies without asking the server first
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			writeServeJSON(w, http.StatusUnsupportedMediaType, serveError{Error: "Content-Type must be application/json"})
+			return
+		}
+
+		var req serveRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxServeRequestSize)).Decode(&req); err != nil {
+			writeServeJSON(w, http.StatusBadRequest, serveError{Error: fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+
+		var warnings []string
+		ctx := withWarnings(r.Context(), func(warning string) {
+			warnings = append(warnings, warning)
+		})
+		var events *serveEvents
+		if req.Stream {
+			events = &serveEvents{w: w}
+			ctx = withDeltas(ctx, func(delta string) {
+				events.write("message", serveResponse{Content: delta})
+			})
+		}
+
+		start := time.Now()
+		resp, err := endpoint(ctx, req)
+		if err != nil {
+			debug.Printf("%s failed: %v", r.URL.Path, err)
+			if events != nil && events.started {
+				// The status was sent with the first chunk
+				events.write("error", serveError{Error: err.Error()})
+				return
+			}
+			var badRequest *badRequestError
+			var limitErr *client.LimitError
+			status := http.StatusBadGateway
+			switch {
+			case errors.As(err, &badRequest):
+				status = http.StatusBadRequest
+			case errors.Is(err, errInvalidPrompt):
+				status = http.StatusInternalServerError
+			case errors.As(err, &limitErr):
+				status = http.StatusTooManyRequests
+			}
+			writeServeJSON(w, status, serveError{Error: err.Error()})
+			return
+		}
+		debug.Printf("%s answered in %s", r.URL.Path, time.Since(start).Round(time.Millisecond))
+
+		response := serveResponse{Content: strings.TrimSpace(resp.Content), Usage: resp.Usage, Warnings: warnings}
+		if events == nil {
+			writeServeJSON(w, http.StatusOK, response)
+			return
+		}
+		// Commit messages are finished after streaming, the event carries the final content
+		events.write("done", response)
+	}
+}
+
+// writeServeJSON writes value as a JSON response
+func writeServeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		debug.Printf("Failed to write response: %v", err)
+	}
+}
+
+// serveEvents writes server-sent events: "message" events carrying the answer chunks as
+// the provider streams them, then a "done" event carrying the whole answer and the usage,
+// or an "error" event.
+// The headers are sent with the first event.
+type serveEvents struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+// write sends an event and flushes it to the client
+func (e *serveEvents) write(event string, value interface{}) {
+	if !e.started {
+		e.w.Header().Set("Content-Type", "text/event-stream")
+		e.w.Header().Set("Cache-Control", "no-cache")
+		e.w.WriteHeader(http.StatusOK)
+		e.started = true
+	}
+	data, _ := json.Marshal(value)
+	fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, data)
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// complete sends prompt to the provider, streaming the answer to the chunk receiver
+// of ctx when requested
+func (s *server) complete(ctx context.Context, prompt string, stream bool) (*types.CompletionResponse, error) {
+	if onDelta, ok := ctx.Value(deltaKey{}).(func(string)); ok && stream {
+		return s.client.Stream(ctx, prompt, nil, onDelta)
+	}
+	return s.client.Chat(ctx, prompt, nil)
+}
+
+// language returns the name of the output language code, output.lang if code is empty
+func (s *server) language(code string) (string, error) {
+	if code == "" {
+		return outputLanguage(s.cfgManager)
+	}
+	if name, ok := config.OutputLanguageMap[code]; ok {
+		return name, nil
+	}
+	return code, nil
+}
+
+// diff returns the diff of req, or the staged changes of req.Repo
+func (s *server) diff(ctx context.Context, req serveRequest) (string, error) {
+	if req.Diff != "" {
+		return req.Diff, nil
+	}
+	if req.Repo == "" {
+		return "", &badRequestError{msg: "diff or repo is required"}
+	}
+	reportProgress(ctx, "Reading staged changes")
+	diff, err := (&git.GitVCS{}).GetStagedDiffFiltered(req.Repo, s.cfgManager)
+	if err != nil {
+		return "", &badRequestError{msg: fmt.Sprintf("failed to get staged diff: %v", err)}
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", &badRequestError{msg: "no staged changes found"}
+	}
+	return diff, nil
+}
+
+// commitMessage generates a commit message, finished like with the commit command
+func (s *server) commitMessage(ctx context.Context, req serveRequest) (*types.CompletionResponse, error) {
+	diff, err := s.diff(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lang, err := s.language(req.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	var vcs git.VCS
+	var scopes []string
+	if req.Repo != "" {
+		gitVCS := &git.GitVCS{}
+		if scopes, _, err = commitmsg.StagedScopes(s.cfgManager, gitVCS, req.Repo); err != nil {
+			return nil, &badRequestError{msg: err.Error()}
+		}
+		vcs = gitVCS
+	}
+	vars := commitPromptVars(s.cfgManager, vcs, req.Repo, diff, req.Hint)
+	vars["lang"], vars["output.lang"] = lang, lang
+	commitPrompt, err := renderPrompt(s.cfgManager.GetPrompt(nil, req.Rich), vars)
+	if err != nil {
+		return nil, err
+	}
+
+	reportProgress(ctx, "Generating commit message")
+	resp, err := s.complete(ctx, commitPrompt, req.Stream && lang == "English")
+	if err != nil {
+		return nil, err
+	}
+
+	finish := commitmsg.Options{
+		Style:  s.cfgManager.GetOutputStyle(),
+		Types:  s.cfgManager.GetCommitTypes(),
+		Scopes: scopes,
+		Warn: func(err error) {
+			reportWarning(ctx, err.Error())
+		},
+	}
+	if lang != "English" {
+		finish.Translate = func(message string) (string, error) {
+			reportProgress(ctx, "Translating commit message to "+lang)
+			translated, err := s.translate(ctx, serveRequest{Message: message, Lang: req.Lang, Stream: req.Stream})
+			if err != nil {
+				return "", err
+			}
+			resp = translated
+			return translated.Content, nil
+		}
+	}
+	message, err := commitmsg.Finish(resp.Content, finish)
+	if err != nil {
+		return nil, err
+	}
+	// resp is the answer of the translation when there was one
+	resp.Content = message
+	return resp, nil
+}
+
+// translate translates a commit message
+func (s *server) translate(ctx context.Context, req serveRequest) (*types.CompletionResponse, error) {
+	if req.Message == "" {
+		return nil, &badRequestError{msg: "message is required"}
+	}
+	lang, err := s.language(req.Lang)
+	if err != nil {
+		return nil, err
+	}
+	translatePrompt, err := renderPrompt(s.cfgManager.GetTranslationPrompt(nil), promptVars{
+		"placeholder": req.Message,
+		"message":     req.Message,
+		"lang":        lang,
+		"output.lang": lang,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.complete(ctx, translatePrompt, req.Stream)
+}
+
+// review reviews a diff
+func (s *server) review(ctx context.Context, req serveRequest) (*types.CompletionResponse, error) {
+	diff, err := s.diff(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lang, err := s.language(req.Lang)
+	if err != nil {
+		return nil, err
+	}
+	reviewPrompt, err := renderPrompt(s.cfgManager.GetNamedPrompt("review"), promptVars{
+		"placeholder": diff,
+		"diff":        diff,
+		"lang":        lang,
+		"output.lang": lang,
+	})
+	if err != nil {
+		return nil, err
+	}
+	reportProgress(ctx, "Reviewing changes")
+	return s.complete(ctx, reviewPrompt, req.Stream)
+}
+
+// explain explains a piece of code
+func (s *server) explain(ctx context.Context, req serveRequest) (*types.CompletionResponse, error) {
+	if req.Code == "" {
+		return nil, &badRequestError{msg: "code is required"}
+	}
+	lang, err := s.language(req.Lang)
+	if err != nil {
+		return nil, err
+	}
+	language := req.Language
+	if language == "" {
+		language = "source"
+	}
+	explainPrompt, err := renderPrompt(s.cfgManager.GetNamedPrompt("explain"), promptVars{
+		"placeholder": req.Code,
+		"code":        req.Code,
+		"language":    language,
+		"lang":        lang,
+		"output.lang": lang,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.complete(ctx, explainPrompt, req.Stream)
+}
+
+// serveListener listens on address, a host:port or a unix socket path prefixed with "unix:"
+func serveListener(address string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		// Remove a socket left behind by a previous server
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			listener.Close()
+			return nil, err
+		}
+		return listener, nil
+	}
+	return net.Listen("tcp", address)
+}
+
+// isLoopback reports whether address only accepts local connections
+func isLoopback(address string) bool {
+	if strings.HasPrefix(address, "unix:") {
+		return true
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// NewServeCmd creates a new serve command
+func NewServeCmd() *cobra.Command {
+	var (
+		listen string
+		token  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve commit messages, translations, reviews and explanations over HTTP",
+		Long: `Run a local HTTP server for editor and IDE integrations.
+
+Endpoints take and return JSON, and answer {"error": "..."} on failure:
+
+  GET  /v1/health          the active provider
+  POST /v1/commit-message  {"diff" or "repo", "rich", "lang", "hint"}
+  POST /v1/translate       {"message", "lang"}
+  POST /v1/review          {"diff" or "repo", "lang"}
+  POST /v1/explain         {"code", "language", "lang"}
+
+Responses are {"content", "usage", "warnings"}. Commit messages are validated against the
+commit types, translated, given the scopes of the staged files and formatted in the output
+style like with the commit command; "warnings" lists the problems found by the validation.
+With "stream": true responses are sent as server-sent events: "message" events with the
+content chunks as the provider generates them, then a "done" event with the final response,
+or an "error" event if the provider fails while streaming.
+Providers without streaming support send their whole answer in one "message" event.
+
+Requests must be sent with "Content-Type: application/json" and a Host naming the listen
+address, e.g. localhost:8765. Requests carrying an Origin header, as sent by web pages,
+are refused. When a token is set with --token or ` + serveTokenEnv + `, requests must send
+it in an "Authorization: Bearer <token>" header.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			clientConfig, err := cfgManager.GetClientConfig()
+			if err != nil {
+				return err
+			}
+			// There is no terminal to confirm requests over a limit, refuse them instead
+			client.SetLimitConfirm(nil)
+
+			if token == "" {
+				token = os.Getenv(serveTokenEnv)
+			}
+			if token == "" && !isLoopback(listen) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s accepts remote connections and no token is set\n", listen)
+			}
+
+			listener, err := serveListener(listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", listen, err)
+			}
+
+			srv := &http.Server{
+				Handler:           newServeHandler(cfgManager, client.New(clientConfig), token, listen),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				srv.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Listening on %s\n", listen)
+			if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8765", "Address to listen on, host:port or unix:/path/to/socket")
+	cmd.Flags().StringVar(&token, "token", "", "Token clients must send as a bearer token (default: $"+serveTokenEnv+")")
+
+	return cmd
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServeHandler(t *testing.T, token string) http.Handler {
+	t.Helper()
+	provider := newMockCompletionServer(t)
+	t.Cleanup(provider.Close)
+
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: `+provider.URL+`
+  model: gpt-4o
+output:
+  lang: en
+`)
+	t.Cleanup(cleanup)
+
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+	clientConfig, err := cfgManager.GetClientConfig()
+	require.NoError(t, err)
+	return newServeHandler(cfgManager, client.New(clientConfig), token, testServeListen)
+}
+
+// testServeListen is the address test handlers listen on
+const testServeListen = "127.0.0.1:8765"
+
+func serveRequestTo(handler http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = testServeListen
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeHandler(t *testing.T) {
+	handler := newTestServeHandler(t, "secret")
+
+	t.Run("requires token", func(t *testing.T) {
+		rec := serveRequestTo(handler, http.MethodGet, "/v1/health", "", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = serveRequestTo(handler, http.MethodGet, "/v1/health", "", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = serveRequestTo(handler, http.MethodGet, "/v1/health", "", "secret")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"ok","provider":"openai"}`, rec.Body.String())
+	})
+
+	t.Run("commit message", func(t *testing.T) {
+		rec := serveRequestTo(handler, http.MethodPost, "/v1/commit-message", `{"diff":"diff --git a/x b/x"}`, "secret")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp serveResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "fix: handle nil config", resp.Content)
+		require.NotNil(t, resp.Usage)
+		assert.Equal(t, 106, resp.Usage.TotalTokens)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		rec := serveRequestTo(handler, http.MethodPost, "/v1/review", `{"diff":"diff --git a/x b/x","stream":true}`, "secret")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "event: message\ndata: {\"content\":\"fix: \"}\n\n"+
+			"event: message\ndata: {\"content\":\"handle \"}\n\n"+
+			"event: message\ndata: {\"content\":\"nil \"}\n\n"+
+			"event: message\ndata: {\"content\":\"config\"}\n\n"+
+			"event: done\ndata: {\"content\":\"fix: handle nil config\",\"usage\":{\"prompt_tokens\":100,\"completion_tokens\":6,\"total_tokens\":106}}\n\n",
+			rec.Body.String())
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			method string
+			path   string
+			body   string
+			status int
+			err    string
+		}{
+			{http.MethodGet, "/v1/translate", "", http.StatusMethodNotAllowed, "method not allowed"},
+			{http.MethodPost, "/v1/translate", "{", http.StatusBadRequest, "invalid request body"},
+			{http.MethodPost, "/v1/translate", `{}`, http.StatusBadRequest, "message is required"},
+			{http.MethodPost, "/v1/explain", `{}`, http.StatusBadRequest, "code is required"},
+			{http.MethodPost, "/v1/commit-message", `{}`, http.StatusBadRequest, "diff or repo is required"},
+		}
+		for _, tt := range tests {
+			rec := serveRequestTo(handler, tt.method, tt.path, tt.body, "secret")
+			assert.Equal(t, tt.status, rec.Code, tt.path)
+			var resp serveError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Contains(t, resp.Error, tt.err)
+		}
+	})
+}
+
+func TestServeCommitMessageFinished(t *testing.T) {
+	provider := newMockCompletionServer(t)
+	defer provider.Close()
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: `+provider.URL+`
+  model: gpt-4o
+output:
+  lang: en
+commit_types:
+  - feat
+scopes:
+  map:
+    core/**: core
+`)
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+	clientConfig, err := cfgManager.GetClientConfig()
+	require.NoError(t, err)
+	handler := newServeHandler(cfgManager, client.New(clientConfig), "", testServeListen)
+
+	_, dir, cleanupRepo := setupTestRepo(t, git.Git)
+	defer cleanupRepo()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "core"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "core", "config.go"), []byte("package core\n"), 0644))
+	require.NoError(t, testutils.RunCommand(t, dir, "git", "add", "."))
+
+	body, err := json.Marshal(serveRequest{Repo: dir})
+	require.NoError(t, err)
+	rec := serveRequestTo(handler, http.MethodPost, "/v1/commit-message", string(body), "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp serveResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "fix(core): handle nil config", resp.Content)
+	assert.Equal(t, []string{`unknown commit type "fix", expected one of feat`}, resp.Warnings)
+}
+
+func TestServeHandlerWithoutToken(t *testing.T) {
+	handler := newTestServeHandler(t, "")
+	rec := serveRequestTo(handler, http.MethodPost, "/v1/explain", `{"code":"func main() {}","language":"Go","lang":"fr"}`, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "fix: handle nil config")
+}
+
+func TestServeHandlerRejectsWebPages(t *testing.T) {
+	handler := newTestServeHandler(t, "")
+	body := `{"diff":"diff --git a/x b/x"}`
+
+	tests := []struct {
+		name   string
+		modify func(req *http.Request)
+		status int
+		err    string
+	}{
+		{"origin", func(req *http.Request) {
+			req.Header.Set("Origin", "https://attacker.example")
+		}, http.StatusForbidden, "web pages"},
+		{"rebound host", func(req *http.Request) {
+			req.Host = "attacker.example:8765"
+		}, http.StatusForbidden, "unexpected Host"},
+		{"other port", func(req *http.Request) {
+			req.Host = "localhost:9000"
+		}, http.StatusForbidden, "unexpected Host"},
+		{"text body", func(req *http.Request) {
+			req.Header.Set("Content-Type", "text/plain")
+		}, http.StatusUnsupportedMediaType, "application/json"},
+		{"no content type", func(req *http.Request) {
+			req.Header.Del("Content-Type")
+		}, http.StatusUnsupportedMediaType, "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/commit-message", strings.NewReader(body))
+			req.Host = testServeListen
+			req.Header.Set("Content-Type", "application/json")
+			tt.modify(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.err)
+		})
+	}
+
+	// Loopback names of the listen port and JSON with a charset are accepted
+	req := httptest.NewRequest(http.MethodPost, "/v1/commit-message", strings.NewReader(body))
+	req.Host = "localhost:8765"
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestAllowedHost(t *testing.T) {
+	assert.True(t, allowedHost("127.0.0.1:8765", "127.0.0.1:8765"))
+	assert.True(t, allowedHost("[::1]:8765", "127.0.0.1:8765"))
+	assert.False(t, allowedHost("evil.example:8765", "127.0.0.1:8765"))
+	assert.True(t, allowedHost("build-box:8765", "0.0.0.0:8765"))
+	assert.True(t, allowedHost("build-box:8765", ":8765"))
+	assert.False(t, allowedHost("build-box:80", ":8765"))
+	assert.True(t, allowedHost("anything", "unix:/tmp/gptcomet.sock"))
+}
+
+func TestIsLoopback(t *testing.T) {
+	assert.True(t, isLoopback("127.0.0.1:8765"))
+	assert.True(t, isLoopback("localhost:8765"))
+	assert.True(t, isLoopback("[::1]:8765"))
+	assert.True(t, isLoopback("unix:/tmp/gptcomet.sock"))
+	assert.False(t, isLoopback("0.0.0.0:8765"))
+	assert.False(t, isLoopback(":8765"))
+}
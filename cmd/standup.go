@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+// collectStandupCommits gathers the commits of author since the given date in every repository,
+// formatted as one section per repository. It returns the text and the number of commits found.
+func collectStandupCommits(vcs *git.GitVCS, repos []string, since, author string) (string, int, error) {
+	var sb strings.Builder
+	total := 0
+	for _, repo := range repos {
+		absRepo, err := filepath.Abs(repo)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to resolve repository path %s: %w", repo, err)
+		}
+
+		repoAuthor := author
+		if repoAuthor == "me" {
+			repoAuthor, err = vcs.GetUserEmail(absRepo)
+			if err != nil {
+				return "", 0, fmt.Errorf("failed to get user.email for %s: %w", absRepo, err)
+			}
+		}
+		debug.Printf("Collecting commits in %s by %s since %s", absRepo, repoAuthor, since)
+
+		commits, err := vcs.GetCommitsSince(absRepo, since, repoAuthor)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to get commits for %s: %w", absRepo, err)
+		}
+		if len(commits) == 0 {
+			continue
+		}
+		total += len(commits)
+
+		fmt.Fprintf(&sb, "Repository: %s\n", filepath.Base(absRepo))
+		for _, c := range commits {
+			fmt.Fprintf(&sb, "- %s %s %s\n", c.ShortHash(), c.Date, c.Subject)
+			if c.Body != "" {
+				for _, line := range strings.Split(c.Body, "\n") {
+					fmt.Fprintf(&sb, "  %s\n", line)
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), total, nil
+}
+
+// NewStandupCmd creates a new standup command
+func NewStandupCmd() *cobra.Command {
+	var (
+		since  string
+		author string
+		repos  []string
+		group  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "standup",
+		Short: "Summarize your recent commits for a standup or weekly report",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if group != "repo" && group != "theme" {
+				return fmt.Errorf("invalid group %q, expected repo or theme", group)
+			}
+
+			if len(repos) == 0 {
+				repoPath, err := os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get current directory: %w", err)
+				}
+				repos = []string{repoPath}
+			}
+
+			commits, count, err := collectStandupCommits(&git.GitVCS{}, repos, since, author)
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				fmt.Printf("No commits found since %s\n", since)
+				return nil
+			}
+			debug.Printf("Found %d commits", count)
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			lang, err := outputLanguage(cfgManager)
+			if err != nil {
+				return err
+			}
+
+			groupBy := "repository"
+			if group == "theme" {
+				groupBy = "theme"
+			}
+			prompt, err := renderPrompt(cfgManager.GetNamedPrompt("standup"), promptVars{
+				"since":       since,
+				"group":       groupBy,
+				"output.lang": lang,
+				"placeholder": commits,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Get client config
+			clientConfig, err := cfgManager.GetClientConfig()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("🤖 Summarizing %d commits...\n", count)
+			resp, err := client.New(clientConfig).Chat(context.Background(), prompt, nil)
+			if err != nil {
+				return fmt.Errorf("failed to summarize commits: %w", err)
+			}
+
+			fmt.Printf("\n%s\n", strings.TrimSpace(resp.Content))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "yesterday", "Only include commits more recent than this date")
+	cmd.Flags().StringVar(&author, "author", "me", "Author pattern to filter commits, \"me\" uses git user.email")
+	cmd.Flags().StringSliceVar(&repos, "repos", nil, "Repositories to collect commits from (default current directory)")
+	cmd.Flags().StringVar(&group, "group", "repo", "Group the summary by repo or theme")
+
+	return cmd
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStandupCmd(t *testing.T) {
+	cmd := NewStandupCmd()
+	require.NotNil(t, cmd)
+
+	for _, name := range []string{"since", "author", "repos", "group"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "flag %q not found", name)
+	}
+	since, _ := cmd.Flags().GetString("since")
+	assert.Equal(t, "yesterday", since)
+}
+
+func TestCollectStandupCommits(t *testing.T) {
+	_, repoPath, cleanup := setupTestRepo(t, git.Git)
+	defer cleanup()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, repoPath, "add", "a.txt"))
+	require.NoError(t, testutils.RunGitCommand(t, repoPath, "commit", "-m", "feat: add a"))
+
+	commits, count, err := collectStandupCommits(&git.GitVCS{}, []string{repoPath}, "1 week ago", "me")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Contains(t, commits, "Repository: "+filepath.Base(repoPath))
+	assert.Contains(t, commits, "feat: add a")
+
+	_, count, err = collectStandupCommits(&git.GitVCS{}, []string{repoPath}, "1 week ago", "nobody@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
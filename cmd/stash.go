@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+// stashSummariesPath returns the cache file of generated stash summaries, next to the config file
+func stashSummariesPath(cfgManager *config.Manager) string {
+	return filepath.Join(filepath.Dir(cfgManager.GetPath()), "stash_summaries.json")
+}
+
+// loadStashSummaries reads the cached summaries keyed by stash commit hash
+func loadStashSummaries(path string) (map[string]string, error) {
+	summaries := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return summaries, nil
+		}
+		return nil, fmt.Errorf("failed to read stash summaries: %w", err)
+	}
+	if err := json.Unmarshal(data, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to parse stash summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// saveStashSummaries writes the cached summaries
+func saveStashSummaries(path string, summaries map[string]string) error {
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stash summaries: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stash summaries: %w", err)
+	}
+	return nil
+}
+
+// summarizeDiff asks the LLM for a one-line summary of diff
+func summarizeDiff(cfgManager *config.Manager, c *client.Client, diff string) (string, error) {
+	prompt, err := renderPrompt(cfgManager.GetNamedPrompt("stash"), promptVars{
+		"placeholder": diff,
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.Chat(context.Background(), prompt, nil)
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(strings.SplitN(strings.TrimSpace(resp.Content), "\n", 2)[0])
+	return strings.Trim(summary, "`\""), nil
+}
+
+// newStashClient loads the config manager and creates a client for the stash commands
+func newStashClient(cmd *cobra.Command) (*config.Manager, *client.Client, error) {
+	// Get config path from root command
+	configPath, err := cmd.Root().PersistentFlags().GetString("config")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+
+	// Create config manager
+	cfgManager, err := config.New(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+
+	// Get client config
+	clientConfig, err := cfgManager.GetClientConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfgManager, client.New(clientConfig), nil
+}
+
+// NewStashCmd creates a new stash command
+func NewStashCmd() *cobra.Command {
+	var (
+		dryRun   bool
+		describe bool
+	)
+
+	pushRunE := func(cmd *cobra.Command, args []string) error {
+		repoPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		debug.Printf("Using repository path: %s", repoPath)
+
+		cfgManager, c, err := newStashClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		vcs := &git.GitVCS{}
+		diff, err := vcs.GetWorkingDiff(repoPath, git.IgnorePatterns(cfgManager))
+		if err != nil {
+			return fmt.Errorf("failed to get diff: %w", err)
+		}
+		if strings.TrimSpace(diff) == "" {
+			return fmt.Errorf("no local changes to stash, ignoring the files matching file_ignore")
+		}
+
+		fmt.Println("🤖 Summarizing your work in progress...")
+		message, err := summarizeDiff(cfgManager, c, diff)
+		if err != nil {
+			return fmt.Errorf("failed to summarize changes: %w", err)
+		}
+		fmt.Printf("\nStash message:\n%s\n", formatCommitMessage(message))
+
+		if dryRun {
+			return nil
+		}
+		if err := vcs.StashPush(repoPath, message); err != nil {
+			return fmt.Errorf("failed to stash changes: %w", err)
+		}
+		fmt.Println("Changes stashed")
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use:   "stash",
+		Short: "Stash changes with a generated message",
+		RunE:  pushRunE,
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated stash message without stashing")
+
+	pushCmd := &cobra.Command{
+		Use:   "push",
+		Short: "Stash staged and unstaged changes with a generated message",
+		RunE:  pushRunE,
+	}
+	pushCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated stash message without stashing")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List stashes, optionally describing those without a message",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+
+			vcs := &git.GitVCS{}
+			stashes, err := vcs.ListStashes(repoPath)
+			if err != nil {
+				return fmt.Errorf("failed to list stashes: %w", err)
+			}
+			if len(stashes) == 0 {
+				fmt.Println("No stashes found")
+				return nil
+			}
+
+			var (
+				cfgManager *config.Manager
+				c          *client.Client
+				summaries  = make(map[string]string)
+				cachePath  string
+				updated    bool
+			)
+			if describe {
+				cfgManager, c, err = newStashClient(cmd)
+				if err != nil {
+					return err
+				}
+				cachePath = stashSummariesPath(cfgManager)
+				summaries, err = loadStashSummaries(cachePath)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, stash := range stashes {
+				message := stash.Message
+				if !stash.IsDescriptive() {
+					if summary, ok := summaries[stash.Hash]; ok {
+						message = summary
+					} else if describe {
+						diff, err := vcs.GetStashDiff(repoPath, stash.Ref, git.IgnorePatterns(cfgManager))
+						if err != nil {
+							return fmt.Errorf("failed to get diff of %s: %w", stash.Ref, err)
+						}
+						if strings.TrimSpace(diff) == "" {
+							// Only ignored files were stashed, keep the git message
+							fmt.Printf("%s  %s  %s\n", successStyle.Render(stash.Ref), message, stash.Date)
+							continue
+						}
+						debug.Printf("Summarizing %s", stash.Ref)
+						summary, err := summarizeDiff(cfgManager, c, diff)
+						if err != nil {
+							return fmt.Errorf("failed to summarize %s: %w", stash.Ref, err)
+						}
+						summaries[stash.Hash] = summary
+						updated = true
+						message = summary
+					}
+				}
+				fmt.Printf("%s  %s  %s\n", successStyle.Render(stash.Ref), message, stash.Date)
+			}
+
+			if updated {
+				return saveStashSummaries(cachePath, summaries)
+			}
+			return nil
+		},
+	}
+	listCmd.Flags().BoolVar(&describe, "describe", false, "Generate summaries for stashes without a descriptive message")
+
+	cmd.AddCommand(pushCmd, listCmd)
+	return cmd
+}
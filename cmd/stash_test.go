@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStashCmd(t *testing.T) {
+	cmd := NewStashCmd()
+	require.NotNil(t, cmd)
+	assert.NotNil(t, cmd.Flags().Lookup("dry-run"))
+
+	subcommands := map[string]bool{}
+	for _, sub := range cmd.Commands() {
+		subcommands[sub.Name()] = true
+	}
+	assert.True(t, subcommands["push"])
+	assert.True(t, subcommands["list"])
+
+	listCmd, _, err := cmd.Find([]string{"list"})
+	require.NoError(t, err)
+	assert.NotNil(t, listCmd.Flags().Lookup("describe"))
+}
+
+func TestStashSummaries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stash_summaries.json")
+
+	summaries, err := loadStashSummaries(path)
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	summaries["abc"] = "Rework main output"
+	require.NoError(t, saveStashSummaries(path, summaries))
+
+	loaded, err := loadStashSummaries(path)
+	require.NoError(t, err)
+	assert.Equal(t, summaries, loaded)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/style"
+
+	"github.com/spf13/cobra"
+)
+
+// styleCachePath returns the learned styles cache file, next to the config file
+func styleCachePath(cfgManager *config.Manager) string {
+	return filepath.Join(filepath.Dir(cfgManager.GetPath()), style.CacheFile)
+}
+
+// learnedStyle returns the commit style of the repository at repoPath, learning it from
+// the history when it is not cached, older than style.CacheTTL or refresh is set
+func learnedStyle(cfgManager *config.Manager, vcs *git.GitVCS, repoPath string, refresh bool) (*style.Profile, error) {
+	styleConfig := cfgManager.GetStyleConfig()
+	repo := historyRepo(vcs, repoPath)
+	cachePath := styleCachePath(cfgManager)
+	cache, err := style.LoadCache(cachePath)
+	if err != nil {
+		debug.Printf("Failed to load style cache: %v", err)
+		cache = make(map[string]*style.Profile)
+	}
+	if profile, ok := cache[repo]; ok && !refresh && time.Since(profile.LearnedAt) < style.CacheTTL {
+		return profile, nil
+	}
+
+	commits, err := vcs.GetRecentCommits(repoPath, styleConfig.Samples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history: %w", err)
+	}
+	profile := style.Learn(commits, styleConfig.Examples)
+	debug.Printf("Learned the commit style of %s from %d commits", repo, profile.Samples)
+
+	cache[repo] = profile
+	if err := style.SaveCache(cachePath, cache); err != nil {
+		debug.Printf("Failed to save style cache: %v", err)
+	}
+	return profile, nil
+}
+
+// formatStyle renders a learned style for the terminal
+func formatStyle(repo string, profile *style.Profile) string {
+	if profile.Samples == 0 {
+		return fmt.Sprintf("No commits to learn the style of %s from\n", repo)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Commit style of %s, learned from %d commits on %s:\n\n", repo, profile.Samples, profile.LearnedAt.Format("2006-01-02 15:04"))
+	b.WriteString(profile.Describe() + "\n")
+	if profile.Language != "" {
+		fmt.Fprintf(&b, "\nThe history is written in %s, generated messages follow output.lang\n", profile.Language)
+	}
+	if len(profile.Examples) > 0 {
+		b.WriteString("\nExamples:\n")
+		for _, example := range profile.Examples {
+			b.WriteString("\n  " + strings.ReplaceAll(example, "\n", "\n  ") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// NewStyleCmd creates a new style command
+func NewStyleCmd() *cobra.Command {
+	var refresh bool
+
+	cmd := &cobra.Command{
+		Use:   "style",
+		Short: "Show the commit style learned from the repository history",
+		Long: `Show the commit message conventions learned from the recent history of the
+repository: types and scopes, casing, subject length, ticket keys and language.
+
+The style and a few example messages are given to the commit message prompts as
+{{ style }} and {{ style_examples }}, so generated messages blend in. It is learned
+from the last style.samples commits, cached for a day, and turned off with
+style.enabled: false.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			vcs := &git.GitVCS{}
+			profile, err := learnedStyle(cfgManager, vcs, repoPath, refresh)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), formatStyle(historyRepo(vcs, repoPath), profile))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Learn the style again instead of using the cached one")
+
+	return cmd
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLearnedStyle(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, "style:\n  examples: 1\n")
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+
+	_, dir, cleanupRepo := setupTestRepo(t, git.Git)
+	defer cleanupRepo()
+	vcs := &git.GitVCS{}
+	commit := func(name, message string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+		require.NoError(t, testutils.RunCommand(t, dir, "git", "add", name))
+		require.NoError(t, testutils.RunCommand(t, dir, "git", "commit", "-m", message))
+	}
+	commit("a.txt", "fix(core): handle a")
+
+	profile, err := learnedStyle(cfgManager, vcs, dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fix(core): handle a"}, profile.Examples)
+
+	// The cached style is reused until it is refreshed
+	commit("b.txt", "feat(core): add b")
+	profile, err = learnedStyle(cfgManager, vcs, dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, profile.Samples)
+
+	profile, err = learnedStyle(cfgManager, vcs, dir, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, profile.Samples)
+	assert.Equal(t, []string{"feat(core): add b"}, profile.Examples)
+
+	out := formatStyle(dir, profile)
+	assert.Contains(t, out, "learned from 2 commits")
+	assert.Contains(t, out, "The usual scopes are core")
+	assert.Contains(t, out, "  feat(core): add b")
+
+	// The style is available to the commit prompts
+	vars := commitPromptVars(cfgManager, vcs, dir, "+b", "")
+	assert.Contains(t, vars["style"], "the usual types are")
+	text, err := renderPrompt(cfgManager.GetPrompt(nil, false), vars)
+	require.NoError(t, err)
+	assert.Contains(t, text, "Follow the commit conventions of this repository:\n- Use the type(scope): summary form")
+	assert.Contains(t, text, "as examples of the style:\n\nfeat(core): add b\n")
+	require.NoError(t, cfgManager.Set("style.enabled", false))
+	vars = commitPromptVars(cfgManager, vcs, dir, "+b", "")
+	assert.Empty(t, vars["style"])
+}
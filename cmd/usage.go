@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+// parseSince parses a relative age such as 7d, 2w or 12h, or a YYYY-MM-DD date
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+
+	if len(value) > 1 {
+		unit := value[len(value)-1]
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			switch unit {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q, use e.g. 7d, 2w, 12h or 2024-01-31", value)
+}
+
+// formatSummaries renders the usage summaries as a table with a total row
+func formatSummaries(summaries []usage.Summary, by string) string {
+	var (
+		b     strings.Builder
+		total = usage.Summary{Key: "total"}
+	)
+	row := func(s usage.Summary) {
+		cost := fmt.Sprintf("$%.4f", s.Cost)
+		if s.Unpriced > 0 {
+			cost += "*"
+		}
+		fmt.Fprintf(&b, "%-32s %8d %12d %12d %12d %12s\n",
+			s.Key, s.Requests, s.PromptTokens, s.CompletionTokens, s.TotalTokens, cost)
+	}
+
+	fmt.Fprintf(&b, "%-32s %8s %12s %12s %12s %12s\n", by, "requests", "prompt", "completion", "total", "cost")
+	for _, s := range summaries {
+		row(s)
+		total.Requests += s.Requests
+		total.PromptTokens += s.PromptTokens
+		total.CompletionTokens += s.CompletionTokens
+		total.TotalTokens += s.TotalTokens
+		total.Cost += s.Cost
+		total.Unpriced += s.Unpriced
+	}
+	row(total)
+
+	if total.Unpriced > 0 {
+		fmt.Fprintf(&b, "\n* %d request(s) use models missing from the price table, set usage.prices.<model>.prompt and .completion (USD per million tokens)\n", total.Unpriced)
+	}
+	return b.String()
+}
+
+// NewUsageCmd creates a new usage command
+func NewUsageCmd() *cobra.Command {
+	var (
+		since string
+		by    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Report token usage and estimated cost from the local ledger",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			ledger := usage.NewLedger(filepath.Join(filepath.Dir(cfgManager.GetPath()), usage.LedgerFile))
+			records, err := ledger.Read(start)
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No requests recorded since %s\n", start.Format("2006-01-02 15:04"))
+				return nil
+			}
+
+			summaries, err := usage.Summarize(records, by, cfgManager.GetUsagePrices())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Usage since %s:\n\n%s", start.Format("2006-01-02 15:04"), formatSummaries(summaries, by))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "30d", "Only include requests since this age (7d, 2w, 12h) or date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&by, "by", "model", "Group by model, repo or day")
+
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/belingud/go-gptcomet/internal/usage"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "7d", want: now.AddDate(0, 0, -7)},
+		{value: "2w", want: now.AddDate(0, 0, -14)},
+		{value: "12h", want: now.Add(-12 * time.Hour)},
+		{value: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestUsageCmd(t *testing.T) {
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+usage:
+  prices:
+    llama3:
+      prompt: 1
+      completion: 2
+`)
+	defer cleanup()
+
+	ledger := usage.NewLedger(filepath.Join(filepath.Dir(configPath), usage.LedgerFile))
+	require.NoError(t, ledger.Append(usage.Record{Time: time.Now(), Provider: "ollama", Model: "llama3", PromptTokens: 1000000, CompletionTokens: 500000, TotalTokens: 1500000}))
+	require.NoError(t, ledger.Append(usage.Record{Time: time.Now(), Provider: "custom", Model: "unknown-model", PromptTokens: 10, TotalTokens: 10}))
+
+	root := &cobra.Command{Use: "gptcomet"}
+	root.PersistentFlags().String("config", configPath, "")
+	root.AddCommand(NewUsageCmd())
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetArgs([]string{"usage", "--since", "1d"})
+	require.NoError(t, root.Execute())
+
+	output := buf.String()
+	assert.Contains(t, output, "ollama/llama3")
+	assert.Contains(t, output, "$2.0000")
+	assert.Contains(t, output, "1 request(s) use models missing from the price table")
+
+	root.SetArgs([]string{"usage", "--by", "week"})
+	assert.Error(t, root.Execute())
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+// parseLineRange parses a "path:start-end" or "path:line" argument
+func parseLineRange(arg string) (string, int, int, error) {
+	idx := strings.LastIndex(arg, ":")
+	if idx <= 0 || idx == len(arg)-1 {
+		return "", 0, 0, fmt.Errorf("invalid line range %q, expected path:start-end", arg)
+	}
+	path, lines := arg[:idx], arg[idx+1:]
+
+	startStr, endStr, found := strings.Cut(lines, "-")
+	if !found {
+		endStr = startStr
+	}
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid start line %q: %w", startStr, err)
+	}
+	end, err := strconv.Atoi(endStr)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid end line %q: %w", endStr, err)
+	}
+	if start < 1 || end < start {
+		return "", 0, 0, fmt.Errorf("invalid line range %d-%d", start, end)
+	}
+	return path, start, end, nil
+}
+
+// whyPromptVars returns the variables of the why prompt for the commits of lines start
+// to end of path
+func whyPromptVars(path string, start, end int, lang string, commits []git.Commit) promptVars {
+	var history strings.Builder
+	for _, c := range commits {
+		history.WriteString(c.String())
+		history.WriteString("\n")
+	}
+	return promptVars{
+		"file":        path,
+		"lines":       fmt.Sprintf("%d-%d", start, end),
+		"output.lang": lang,
+		"placeholder": history.String(),
+	}
+}
+
+// NewWhyCmd creates a new why command
+func NewWhyCmd() *cobra.Command {
+	var maxCommits int
+
+	cmd := &cobra.Command{
+		Use:   "why path:start-end",
+		Short: "Explain the history behind a range of lines",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, start, end, err := parseLineRange(args[0])
+			if err != nil {
+				return err
+			}
+
+			repoPath, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+			debug.Printf("Using repository path: %s", repoPath)
+
+			vcs := &git.GitVCS{}
+			commits, err := vcs.GetLineHistory(repoPath, path, start, end, maxCommits)
+			if err != nil {
+				return fmt.Errorf("failed to get line history: %w", err)
+			}
+			if len(commits) == 0 {
+				return fmt.Errorf("no history found for %s", args[0])
+			}
+			debug.Printf("Found %d commits for %s", len(commits), args[0])
+
+			// Get config path from root command
+			configPath, err := cmd.Root().PersistentFlags().GetString("config")
+			if err != nil {
+				return fmt.Errorf("failed to get config path: %w", err)
+			}
+
+			// Create config manager
+			cfgManager, err := config.New(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to create config manager: %w", err)
+			}
+
+			lang, err := outputLanguage(cfgManager)
+			if err != nil {
+				return err
+			}
+
+			prompt, err := renderPrompt(cfgManager.GetNamedPrompt("why"), whyPromptVars(path, start, end, lang, commits))
+			if err != nil {
+				return err
+			}
+
+			// Get client config
+			clientConfig, err := cfgManager.GetClientConfig()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Lines %d-%d of %s were shaped by %d commits:\n", start, end, path, len(commits))
+			for _, c := range commits {
+				fmt.Printf("  %s %s %s\n", c.ShortHash(), c.Date, c.Subject)
+			}
+			fmt.Println("🤖 Digging through the history...")
+
+			resp, err := client.New(clientConfig).Chat(context.Background(), prompt, nil)
+			if err != nil {
+				return fmt.Errorf("failed to explain history: %w", err)
+			}
+
+			fmt.Printf("\n%s\n", strings.TrimSpace(resp.Content))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&maxCommits, "max-commits", "n", 20, "Maximum number of commits to include")
+
+	return cmd
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/pkg/config/defaults"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWhyCmd(t *testing.T) {
+	cmd := NewWhyCmd()
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "why path:start-end", cmd.Use)
+	assert.NotNil(t, cmd.Flags().Lookup("max-commits"))
+	assert.Error(t, cmd.Args(cmd, []string{}))
+}
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		arg       string
+		wantPath  string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{"range", "internal/git/git.go:10-20", "internal/git/git.go", 10, 20, false},
+		{"single line", "main.go:7", "main.go", 7, 7, false},
+		{"colon in path", "dir:name/file.go:3-4", "dir:name/file.go", 3, 4, false},
+		{"missing range", "main.go", "", 0, 0, true},
+		{"empty range", "main.go:", "", 0, 0, true},
+		{"not a number", "main.go:a-b", "", 0, 0, true},
+		{"reversed", "main.go:20-10", "", 0, 0, true},
+		{"zero line", "main.go:0-3", "", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, start, end, err := parseLineRange(tt.arg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestWhyPromptRender(t *testing.T) {
+	commits := []git.Commit{{Hash: "abcdef123", Date: "2024-01-02", Subject: "fix: guard nil config"}}
+	out, err := renderPrompt(defaults.PromptDefaults["why"], whyPromptVars("main.go", 10, 20, "English", commits))
+	require.NoError(t, err)
+	assert.Contains(t, out, "Explain why lines 10-20 of main.go look")
+	assert.Contains(t, out, "answer in English")
+	assert.Contains(t, out, "fix: guard nil config")
+}
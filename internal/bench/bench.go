@@ -0,0 +1,239 @@
+package bench
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/belingud/go-gptcomet/pkg/types"
+)
+
+// Sample is a diff sent to every benchmarked provider
+type Sample struct {
+	ID   string `json:"id"`
+	Diff string `json:"diff"`
+}
+
+// LoadSamples reads the samples of paths. A .jsonl file holds one {"id", "diff"} object
+// per line, any other file is a single diff named after the file.
+func LoadSamples(paths []string) ([]Sample, error) {
+	var samples []Sample
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".jsonl") {
+			loaded, err := loadJSONL(path)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, loaded...)
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sample: %w", err)
+		}
+		if strings.TrimSpace(string(data)) == "" {
+			return nil, fmt.Errorf("sample %s is empty", path)
+		}
+		samples = append(samples, Sample{ID: filepath.Base(path), Diff: string(data)})
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples found")
+	}
+	return samples, nil
+}
+
+// loadJSONL reads the samples of a JSONL file, naming samples without an id after their line number
+func loadJSONL(path string) ([]Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open samples: %w", err)
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var sample Sample
+		if err := json.Unmarshal([]byte(text), &sample); err != nil {
+			return nil, fmt.Errorf("invalid sample on line %d of %s: %w", line, path, err)
+		}
+		if sample.Diff == "" {
+			return nil, fmt.Errorf("sample on line %d of %s has no diff", line, path)
+		}
+		if sample.ID == "" {
+			sample.ID = filepath.Base(path) + ":" + strconv.Itoa(line)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read samples: %w", err)
+	}
+	return samples, nil
+}
+
+// Result is the outcome of one request
+type Result struct {
+	Target    string
+	SampleID  string
+	Latency   time.Duration
+	FirstByte time.Duration
+	Usage     *types.Usage
+	Err       error
+}
+
+// Request sends the sample to the target and returns its response
+type Request func(ctx context.Context, target string, sample Sample) (*types.CompletionResponse, error)
+
+// Run sends every sample runs times to every target, keeping at most concurrency requests
+// in flight. Requests alternate between targets so sequential runs are spread evenly over time.
+// progress, if not nil, is called after each request.
+func Run(ctx context.Context, targets []string, samples []Sample, runs, concurrency int, send Request, progress func(done, total int)) []Result {
+	if runs < 1 {
+		runs = 1
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	type job struct {
+		target string
+		sample Sample
+	}
+	var jobs []job
+	for run := 0; run < runs; run++ {
+		for _, sample := range samples {
+			for _, target := range targets {
+				jobs = append(jobs, job{target: target, sample: sample})
+			}
+		}
+	}
+
+	results := make([]Result, len(jobs))
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	sem := make(chan struct{}, concurrency)
+	for i, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			resp, err := send(ctx, j.target, j.sample)
+			result := Result{Target: j.target, SampleID: j.sample.ID, Latency: time.Since(start), Err: err}
+			if err == nil {
+				result.FirstByte = resp.FirstByte
+				result.Usage = resp.Usage
+			}
+			results[i] = result
+
+			if progress != nil {
+				mu.Lock()
+				done++
+				progress(done, len(jobs))
+				mu.Unlock()
+			}
+		}(i, j)
+	}
+	wg.Wait()
+	return results
+}
+
+// Percentile returns the nearest-rank percentile p (0-100) of durations
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Summary aggregates the results of a target
+type Summary struct {
+	Target   string
+	Requests int
+	Failures int
+	P50      time.Duration
+	P95      time.Duration
+	// FirstByteP50 is the median time to first response, zero if it was not measured
+	FirstByteP50 time.Duration
+	// UsageSamples is the number of successful requests that reported their token usage
+	UsageSamples     int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// FailureRate returns the share of failed requests
+func (s Summary) FailureRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Requests)
+}
+
+// AvgCompletionTokens returns the average completion tokens per request reporting usage
+func (s Summary) AvgCompletionTokens() float64 {
+	if s.UsageSamples == 0 {
+		return 0
+	}
+	return float64(s.CompletionTokens) / float64(s.UsageSamples)
+}
+
+// Summarize aggregates results per target, in the order of targets
+func Summarize(targets []string, results []Result) []Summary {
+	summaries := make([]Summary, 0, len(targets))
+	for _, target := range targets {
+		s := Summary{Target: target}
+		var latencies, firstBytes []time.Duration
+		for _, r := range results {
+			if r.Target != target {
+				continue
+			}
+			s.Requests++
+			if r.Err != nil {
+				s.Failures++
+				continue
+			}
+			latencies = append(latencies, r.Latency)
+			if r.FirstByte > 0 {
+				firstBytes = append(firstBytes, r.FirstByte)
+			}
+			if r.Usage != nil {
+				s.UsageSamples++
+				s.PromptTokens += r.Usage.PromptTokens
+				s.CompletionTokens += r.Usage.CompletionTokens
+			}
+		}
+		s.P50 = Percentile(latencies, 50)
+		s.P95 = Percentile(latencies, 95)
+		s.FirstByteP50 = Percentile(firstBytes, 50)
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
@@ -0,0 +1,111 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSamples(t *testing.T) {
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "samples.jsonl")
+	require.NoError(t, os.WriteFile(jsonl, []byte(`{"id":"a","diff":"diff a"}
+{"diff":"diff b"}
+`), 0644))
+	patch := filepath.Join(dir, "change.diff")
+	require.NoError(t, os.WriteFile(patch, []byte("diff c"), 0644))
+
+	samples, err := LoadSamples([]string{jsonl, patch})
+	require.NoError(t, err)
+	assert.Equal(t, []Sample{
+		{ID: "a", Diff: "diff a"},
+		{ID: "samples.jsonl:2", Diff: "diff b"},
+		{ID: "change.diff", Diff: "diff c"},
+	}, samples)
+
+	empty := filepath.Join(dir, "empty.diff")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	_, err = LoadSamples([]string{empty})
+	assert.Error(t, err)
+
+	_, err = LoadSamples([]string{filepath.Join(dir, "missing.diff")})
+	assert.Error(t, err)
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 10*time.Millisecond, Percentile(durations, 50))
+	assert.Equal(t, 19*time.Millisecond, Percentile(durations, 95))
+	assert.Equal(t, 1*time.Millisecond, Percentile(durations, 0))
+	assert.Equal(t, time.Duration(0), Percentile(nil, 50))
+	// the input is left unsorted
+	assert.Equal(t, 20*time.Millisecond, durations[0])
+}
+
+func TestRunAndSummarize(t *testing.T) {
+	samples := []Sample{{ID: "1", Diff: "a"}, {ID: "2", Diff: "b"}}
+	targets := []string{"fast", "flaky"}
+
+	var inFlight, maxInFlight int32
+	var calls int32
+	send := func(ctx context.Context, target string, sample Sample) (*types.CompletionResponse, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if target == "flaky" && atomic.AddInt32(&calls, 1)%2 == 0 {
+			return nil, errors.New("overloaded")
+		}
+		resp := &types.CompletionResponse{FirstByte: time.Millisecond}
+		if target == "fast" {
+			resp.Usage = &types.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110}
+		}
+		return resp, nil
+	}
+
+	var lastDone, lastTotal int
+	results := Run(context.Background(), targets, samples, 3, 2, send, func(done, total int) {
+		lastDone, lastTotal = done, total
+	})
+	require.Len(t, results, 12)
+	assert.Equal(t, 12, lastDone)
+	assert.Equal(t, 12, lastTotal)
+	assert.LessOrEqual(t, maxInFlight, int32(2))
+
+	summaries := Summarize(targets, results)
+	require.Len(t, summaries, 2)
+
+	fast := summaries[0]
+	assert.Equal(t, "fast", fast.Target)
+	assert.Equal(t, 6, fast.Requests)
+	assert.Equal(t, 0.0, fast.FailureRate())
+	assert.Equal(t, 6, fast.UsageSamples)
+	assert.Equal(t, 600, fast.PromptTokens)
+	assert.Equal(t, 10.0, fast.AvgCompletionTokens())
+	assert.Equal(t, time.Millisecond, fast.FirstByteP50)
+	assert.GreaterOrEqual(t, fast.P95, fast.P50)
+	assert.GreaterOrEqual(t, fast.P50, 5*time.Millisecond)
+
+	flaky := summaries[1]
+	assert.Equal(t, 6, flaky.Requests)
+	assert.Equal(t, 0.5, flaky.FailureRate())
+	assert.Equal(t, 0, flaky.UsageSamples)
+	assert.Equal(t, 0.0, flaky.AvgCompletionTokens())
+}
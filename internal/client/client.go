@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -15,6 +17,8 @@ import (
 
 	"github.com/belingud/go-gptcomet/internal/debug"
 	"github.com/belingud/go-gptcomet/internal/llm"
+	"github.com/belingud/go-gptcomet/internal/prompt"
+	"github.com/belingud/go-gptcomet/internal/usage"
 	"github.com/belingud/go-gptcomet/pkg/types"
 )
 
@@ -22,6 +26,19 @@ import (
 type Client struct {
 	config *types.ClientConfig
 	llm    llm.LLM
+	// httpClient replaces the client built from the proxy and timeout settings when set
+	httpClient *http.Client
+	// notify receives the notices of this client instead of output when set
+	notify func(message string)
+}
+
+// output receives notices such as a fallback to another provider, nil to discard them
+var output io.Writer
+
+// SetOutput sets where notices such as a fallback to another provider are printed.
+// Nothing is printed by default, so the package can be used as a library.
+func SetOutput(w io.Writer) {
+	output = w
 }
 
 // New creates a new client with the given config
@@ -59,8 +76,12 @@ func New(config *types.ClientConfig) *Client {
 	case "sambanova":
 		provider = llm.NewSambanovaLLM(config)
 	default:
-		// Default to OpenAI if provider is not specified
-		provider = llm.NewOpenAILLM(config)
+		// Use a provider registered with llm.RegisterProvider, or default to OpenAI
+		var err error
+		provider, err = llm.NewProvider(config.Provider, config)
+		if err != nil {
+			provider = llm.NewOpenAILLM(config)
+		}
 	}
 
 	return &Client{
@@ -71,20 +92,131 @@ func New(config *types.ClientConfig) *Client {
 
 // Chat sends a chat message to the LLM provider
 func (c *Client) Chat(ctx context.Context, message string, history []types.Message) (*types.CompletionResponse, error) {
+	return c.complete(ctx, message, history, nil)
+}
+
+// complete checks the limits, sends the message to the provider and records the reported
+// token usage. When onDelta is set the answer is streamed to it, in a single chunk if the
+// provider does not stream.
+func (c *Client) complete(ctx context.Context, message string, history []types.Message, onDelta func(string)) (*types.CompletionResponse, error) {
+	if err := c.checkLimits(message, history); err != nil {
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) {
+			return nil, err
+		}
+		switch {
+		case c.config.Fallback != nil:
+			c.notice(fmt.Sprintf("%v, falling back to %s", limitErr, c.config.Fallback.Provider))
+			fallback := New(c.config.Fallback)
+			fallback.httpClient = c.httpClient
+			fallback.notify = c.notify
+			return fallback.complete(ctx, message, history, onDelta)
+		case c.config.Limits.Confirm && confirmLimit != nil && confirmLimit(limitErr):
+			debug.Printf("Sending request over limit %s after confirmation", limitErr.Limit)
+		default:
+			return nil, err
+		}
+	}
+
 	client, err := c.getClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
+	recorder := &usageRecorder{base: client.Transport}
+	client.Transport = recorder
+
+	start := time.Now()
+	resp := &types.CompletionResponse{Raw: make(map[string]interface{})}
+	if streamer, ok := c.llm.(llm.ChunkStreamer); ok && onDelta != nil && streamer.StreamsChunks() {
+		var firstDelta time.Time
+		resp.Content, resp.Usage, err = llm.StreamChat(ctx, client, streamer, message, history, func(delta string) {
+			if firstDelta.IsZero() {
+				firstDelta = time.Now()
+			}
+			onDelta(delta)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+		if !firstDelta.IsZero() {
+			resp.FirstByte = firstDelta.Sub(start)
+		}
+	} else {
+		resp.Content, err = c.llm.MakeRequest(ctx, client, message, history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+		resp.Usage = recorder.Usage()
+		resp.FirstByte = recorder.FirstByte(start)
+		if onDelta != nil {
+			onDelta(resp.Content)
+		}
+	}
 
-	content, err := c.llm.MakeRequest(ctx, client, message, history)
+	record := usage.Record{
+		Time:       start,
+		Provider:   c.config.Provider,
+		Model:      c.config.Model,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if resp.Usage != nil {
+		record.PromptTokens = resp.Usage.PromptTokens
+		record.CompletionTokens = resp.Usage.CompletionTokens
+		record.TotalTokens = resp.Usage.TotalTokens
+		limits := c.config.Limits
+		record.Cost = (float64(record.PromptTokens)*limits.PromptPrice + float64(record.CompletionTokens)*limits.CompletionPrice) / 1e6
+	}
+	usage.Add(record)
+	if path := c.config.Limits.Ledger; path != "" && !usage.Recording(path) {
+		// The budgets are checked against this ledger, also when recording is not enabled
+		if err := usage.NewLedger(path).Append(record); err != nil {
+			debug.Printf("Failed to record usage: %v", err)
+		}
+	}
+
+	return resp, nil
+}
+
+// ListModels returns the models available from the provider, if it supports listing them
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	lister, ok := c.llm.(llm.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support listing models", c.config.Provider)
+	}
+
+	client, err := c.getClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
 
-	return &types.CompletionResponse{
-		Content: content,
-		Raw:     make(map[string]interface{}),
-	}, nil
+	models, err := lister.ListModels(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	return models, nil
+}
+
+// CheckConnection verifies that the configured API base can be reached through the
+// configured proxy. Any HTTP response counts as reachable, since most API bases
+// answer plain GET requests with an error status.
+func (c *Client) CheckConnection(ctx context.Context) error {
+	client, err := c.getClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.APIBase, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.config.APIBase, err)
+	}
+	resp.Body.Close()
+	debug.Printf("API base %s answered with status %d", c.config.APIBase, resp.StatusCode)
+	return nil
 }
 
 // createProxyTransport creates an http.Transport with proxy settings based on the configuration
@@ -158,8 +290,39 @@ func (c *Client) sendRawRequest(req *types.CompletionRequest) (string, error) {
 	return c.llm.MakeRequest(context.Background(), client, req.Messages[len(req.Messages)-1].Content, req.Messages[:len(req.Messages)-1])
 }
 
+// SetHTTPClient makes the client send requests with httpClient instead of a client
+// built from the proxy and timeout settings
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetNotify makes the client pass its notices, such as a fallback to another provider,
+// to notify instead of printing them to the output set with SetOutput
+func (c *Client) SetNotify(notify func(message string)) {
+	c.notify = notify
+}
+
+// notice passes message to the notify function of the client, or prints it to output
+func (c *Client) notice(message string) {
+	switch {
+	case c.notify != nil:
+		c.notify(message)
+	case output != nil:
+		fmt.Fprintln(output, message)
+	}
+}
+
 // getClient returns an HTTP client configured with proxy settings if specified
 func (c *Client) getClient() (*http.Client, error) {
+	if c.httpClient != nil {
+		// Copy the client, its transport is wrapped for every request
+		client := *c.httpClient
+		if client.Transport == nil {
+			client.Transport = http.DefaultTransport
+		}
+		return &client, nil
+	}
+
 	// Create a transport with proxy if configured
 	transport, err := c.createProxyTransport()
 	if err != nil {
@@ -175,10 +338,18 @@ func (c *Client) getClient() (*http.Client, error) {
 	return client, nil
 }
 
-// TranslateMessage translates the given message to the specified language
-func (c *Client) TranslateMessage(prompt string, message string, lang string) (string, error) {
-	// Format the prompt
-	formattedPrompt := fmt.Sprintf(prompt, message, lang)
+// TranslateMessage translates the given message to the language named lang, e.g. "French"
+func (c *Client) TranslateMessage(promptTemplate string, message string, lang string) (string, error) {
+	// Render the prompt
+	formattedPrompt, err := prompt.Render(promptTemplate, prompt.Vars{
+		"placeholder": message,
+		"message":     message,
+		"lang":        lang,
+		"output.lang": lang,
+	})
+	if err != nil {
+		return "", err
+	}
 
 	// Send the request
 	resp, err := c.Chat(context.Background(), formattedPrompt, nil)
@@ -189,9 +360,12 @@ func (c *Client) TranslateMessage(prompt string, message string, lang string) (s
 	return strings.TrimSpace(resp.Content), nil
 }
 
-// GenerateCommitMessage generates a commit message for the given diff
-func (c *Client) GenerateCommitMessage(diff string, prompt string) (string, error) {
-	formattedPrompt := fmt.Sprintf(prompt, diff)
+// GenerateCommitMessage generates a commit message with the prompt template rendered with vars
+func (c *Client) GenerateCommitMessage(promptTemplate string, vars prompt.Vars) (string, error) {
+	formattedPrompt, err := prompt.Render(promptTemplate, vars)
+	if err != nil {
+		return "", err
+	}
 
 	// Send the request
 	resp, err := c.Chat(context.Background(), formattedPrompt, nil)
@@ -216,20 +390,9 @@ func (c *Client) GenerateCodeExplanation(message, lang string) (string, error) {
 	return strings.TrimSpace(resp.Content), nil
 }
 
-// Stream sends a chat message to the LLM provider and streams the response
-func (c *Client) Stream(ctx context.Context, message string, history []types.Message) (*types.CompletionResponse, error) {
-	client, err := c.getClient()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get client: %w", err)
-	}
-
-	content, err := c.llm.MakeRequest(ctx, client, message, history)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-
-	return &types.CompletionResponse{
-		Content: content,
-		Raw:     make(map[string]interface{}),
-	}, nil
+// Stream sends a chat message to the LLM provider and calls onDelta with the chunks of
+// the answer as they arrive. Providers that do not stream send their whole answer as a
+// single chunk.
+func (c *Client) Stream(ctx context.Context, message string, history []types.Message, onDelta func(string)) (*types.CompletionResponse, error) {
+	return c.complete(ctx, message, history, onDelta)
 }
@@ -3,10 +3,14 @@ package client
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/belingud/go-gptcomet/internal/llm"
+	"github.com/belingud/go-gptcomet/internal/prompt"
 	"github.com/belingud/go-gptcomet/pkg/config"
 	"github.com/belingud/go-gptcomet/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -179,7 +183,7 @@ func TestTranslateMessage(t *testing.T) {
 		llm:    mockLLM,
 	}
 
-	translated, err := client.TranslateMessage("translate to %s: %s", "hello", "fr")
+	translated, err := client.TranslateMessage("translate to {{ output.lang }}: {{ placeholder }}", "hello", "French")
 	require.NoError(t, err)
 	assert.Equal(t, "translated message", translated)
 }
@@ -197,9 +201,12 @@ func TestGenerateCommitMessage(t *testing.T) {
 		llm:    mockLLM,
 	}
 
-	msg, err := client.GenerateCommitMessage("diff", "generate commit message for: %s")
+	msg, err := client.GenerateCommitMessage("generate commit message for: {{ diff }}", prompt.Vars{"diff": "diff"})
 	require.NoError(t, err)
 	assert.Equal(t, "commit message", msg)
+
+	_, err = client.GenerateCommitMessage("generate commit message for: {{ branch }}", prompt.Vars{"diff": "diff"})
+	assert.Error(t, err)
 }
 
 func TestGenerateCodeExplanation(t *testing.T) {
@@ -219,3 +226,89 @@ func TestGenerateCodeExplanation(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "code explanation", explanation)
 }
+
+func TestCheckConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{config: &types.ClientConfig{APIBase: server.URL, Timeout: 10}}
+	assert.NoError(t, client.CheckConnection(context.Background()))
+
+	server.Close()
+	assert.Error(t, client.CheckConnection(context.Background()))
+}
+
+func TestChatUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"OK"}}],"usage":{"prompt_tokens":8,"completion_tokens":1,"total_tokens":9}}`))
+	}))
+	defer server.Close()
+
+	client := New(&types.ClientConfig{
+		Provider: "openai",
+		APIBase:  server.URL,
+		APIKey:   "test",
+		Model:    "gpt-4o",
+		Timeout:  10,
+	})
+
+	resp, err := client.Chat(context.Background(), "test message", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", resp.Content)
+	require.NotNil(t, resp.Usage)
+	assert.Equal(t, types.Usage{PromptTokens: 8, CompletionTokens: 1, TotalTokens: 9}, *resp.Usage)
+	assert.Greater(t, resp.FirstByte, time.Duration(0))
+}
+
+func TestStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"stream":true`)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"fix: \"}}]}\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"handle nil\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[],\"usage\":{\"prompt_tokens\":8,\"completion_tokens\":3,\"total_tokens\":11}}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := New(&types.ClientConfig{
+		Provider: "openai",
+		APIBase:  server.URL,
+		APIKey:   "test",
+		Model:    "gpt-4o",
+		Timeout:  10,
+	})
+
+	var deltas []string
+	start := time.Now()
+	resp, err := client.Stream(context.Background(), "test message", nil, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	latency := time.Since(start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fix: ", "handle nil"}, deltas)
+	assert.Equal(t, "fix: handle nil", resp.Content)
+	require.NotNil(t, resp.Usage)
+	assert.Equal(t, 11, resp.Usage.TotalTokens)
+	assert.Greater(t, resp.FirstByte, time.Duration(0))
+	// The time to first token is measured on the first chunk, before the end of the answer
+	assert.Less(t, resp.FirstByte, latency-40*time.Millisecond)
+
+	// Providers that do not stream send their whole answer as one chunk
+	ollama := New(&types.ClientConfig{Provider: "ollama", APIBase: server.URL, Model: "llama3", Timeout: 10})
+	ollama.llm = &MockLLM{makeRequestFunc: func(ctx context.Context, client *http.Client, message string, history []types.Message) (string, error) {
+		return "fix: handle nil", nil
+	}}
+	deltas = nil
+	_, err = ollama.Stream(context.Background(), "test message", nil, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fix: handle nil"}, deltas)
+}
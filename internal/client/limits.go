@@ -0,0 +1,142 @@
+package client
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/belingud/go-gptcomet/internal/usage"
+	"github.com/belingud/go-gptcomet/pkg/types"
+)
+
+// LimitError is returned when a request would exceed a configured limit
+type LimitError struct {
+	Provider string
+	// Limit is the config key of the exceeded limit, e.g. daily_token_budget
+	Limit     string
+	Used      float64
+	Requested float64
+	Max       float64
+}
+
+// Error describes the exceeded limit
+func (e *LimitError) Error() string {
+	format := "%.0f"
+	if e.Limit == "daily_cost_budget" || e.Limit == "monthly_cost_budget" {
+		format = "$%.4f"
+	}
+	if e.Limit == "max_prompt_tokens" {
+		return fmt.Sprintf("request would exceed %s.%s: "+format+" estimated > "+format,
+			e.Provider, e.Limit, e.Requested, e.Max)
+	}
+	return fmt.Sprintf("request would exceed %s.%s: "+format+" used + "+format+" estimated > "+format,
+		e.Provider, e.Limit, e.Used, e.Requested, e.Max)
+}
+
+// confirmLimit asks whether a request exceeding a limit should be sent anyway
+var confirmLimit func(err *LimitError) bool
+
+// SetLimitConfirm sets the function asking for confirmation when a request would exceed
+// a limit and the provider allows confirming. Without it such requests are refused.
+func SetLimitConfirm(confirm func(err *LimitError) bool) {
+	confirmLimit = confirm
+}
+
+// EstimateTokens roughly estimates the prompt tokens of a request without a tokenizer:
+// about four ASCII characters per token, and one token per other character
+func EstimateTokens(message string, history []types.Message) int {
+	count := func(text string) int {
+		ascii := 0
+		other := 0
+		for _, r := range text {
+			if r < utf8.RuneSelf {
+				ascii++
+			} else {
+				other++
+			}
+		}
+		return (ascii+3)/4 + other
+	}
+
+	tokens := count(message)
+	for _, m := range history {
+		tokens += count(m.Content)
+	}
+	return tokens
+}
+
+// checkLimits returns a *LimitError if sending the request would exceed one of the limits
+func (c *Client) checkLimits(message string, history []types.Message) error {
+	limits := c.config.Limits
+	estimate := EstimateTokens(message, history)
+
+	if limits.MaxPromptTokens > 0 && estimate > limits.MaxPromptTokens {
+		return &LimitError{
+			Provider:  c.config.Provider,
+			Limit:     "max_prompt_tokens",
+			Requested: float64(estimate),
+			Max:       float64(limits.MaxPromptTokens),
+		}
+	}
+	if !limits.HasBudget() {
+		return nil
+	}
+
+	now := time.Now()
+	periods := []struct {
+		start      time.Time
+		tokenLimit string
+		tokens     int
+		costLimit  string
+		cost       float64
+	}{
+		{
+			start:      time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+			tokenLimit: "daily_token_budget",
+			tokens:     limits.DailyTokenBudget,
+			costLimit:  "daily_cost_budget",
+			cost:       limits.DailyCostBudget,
+		},
+		{
+			start:      time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()),
+			tokenLimit: "monthly_token_budget",
+			tokens:     limits.MonthlyTokenBudget,
+			costLimit:  "monthly_cost_budget",
+			cost:       limits.MonthlyCostBudget,
+		},
+	}
+
+	if limits.Ledger == "" {
+		return fmt.Errorf("%s has a token or cost budget, budgets need the usage ledger of a config file", c.config.Provider)
+	}
+	ledger := usage.NewLedger(limits.Ledger)
+	estimatedCost := float64(estimate) * limits.PromptPrice / 1e6
+	for _, period := range periods {
+		if period.tokens <= 0 && period.cost <= 0 {
+			continue
+		}
+		usedTokens, usedCost, err := ledger.Spent(c.config.Provider, period.start)
+		if err != nil {
+			return fmt.Errorf("failed to read usage ledger: %w", err)
+		}
+		if period.tokens > 0 && usedTokens+estimate > period.tokens {
+			return &LimitError{
+				Provider:  c.config.Provider,
+				Limit:     period.tokenLimit,
+				Used:      float64(usedTokens),
+				Requested: float64(estimate),
+				Max:       float64(period.tokens),
+			}
+		}
+		if period.cost > 0 && usedCost+estimatedCost > period.cost {
+			return &LimitError{
+				Provider:  c.config.Provider,
+				Limit:     period.costLimit,
+				Used:      usedCost,
+				Requested: estimatedCost,
+				Max:       period.cost,
+			}
+		}
+	}
+	return nil
+}
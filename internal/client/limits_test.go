@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/belingud/go-gptcomet/internal/usage"
+	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens("", nil))
+	assert.Equal(t, 2, EstimateTokens("hello!!", nil))
+	assert.Equal(t, 3, EstimateTokens("你好", []types.Message{{Content: "abcd"}}))
+}
+
+func TestCheckLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), usage.LedgerFile)
+	require.NoError(t, usage.NewLedger(path).Append(usage.Record{
+		Time:        time.Now(),
+		Provider:    "openai",
+		TotalTokens: 900,
+		Cost:        0.5,
+	}))
+
+	tests := []struct {
+		name      string
+		limits    types.Limits
+		message   string
+		wantLimit string
+	}{
+		{name: "no limits", message: "hello"},
+		{
+			name:      "max prompt tokens",
+			limits:    types.Limits{MaxPromptTokens: 10},
+			message:   strings.Repeat("word ", 20),
+			wantLimit: "max_prompt_tokens",
+		},
+		{
+			name:    "within daily tokens",
+			limits:  types.Limits{DailyTokenBudget: 1000, Ledger: path},
+			message: "hello",
+		},
+		{
+			name:      "daily tokens",
+			limits:    types.Limits{DailyTokenBudget: 1000, Ledger: path},
+			message:   strings.Repeat("word ", 100),
+			wantLimit: "daily_token_budget",
+		},
+		{
+			name:      "monthly cost",
+			limits:    types.Limits{MonthlyCostBudget: 0.5, PromptPrice: 1, Ledger: path},
+			message:   "hello",
+			wantLimit: "monthly_cost_budget",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{config: &types.ClientConfig{Provider: "openai", Limits: tt.limits}}
+			err := c.checkLimits(tt.message, nil)
+			if tt.wantLimit == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var limitErr *LimitError
+			require.True(t, errors.As(err, &limitErr))
+			assert.Equal(t, tt.wantLimit, limitErr.Limit)
+			assert.Contains(t, limitErr.Error(), "openai."+tt.wantLimit)
+		})
+	}
+
+	// A budget cannot be checked without a ledger
+	c := &Client{config: &types.ClientConfig{Provider: "openai", Limits: types.Limits{DailyTokenBudget: 1000}}}
+	assert.ErrorContains(t, c.checkLimits("hello", nil), "budgets need the usage ledger")
+}
+
+func TestBudgetWithoutCommandLine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"fix: typo"}}],"usage":{"prompt_tokens":40,"completion_tokens":20,"total_tokens":60}}`))
+	}))
+	defer server.Close()
+
+	// Only the main package enables recording, a client built from a config must not need it
+	configPath, cleanup := testutils.TestConfig(t, `
+provider: openai
+openai:
+  api_key: sk-test
+  api_base: `+server.URL+`
+  model: gpt-4o
+  daily_token_budget: 100
+budget:
+  on_exceed: refuse
+`)
+	defer cleanup()
+	cfgManager, err := config.New(configPath)
+	require.NoError(t, err)
+	clientConfig, err := cfgManager.GetClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(configPath), usage.LedgerFile), clientConfig.Limits.Ledger)
+
+	c := New(clientConfig)
+	_, err = c.Chat(context.Background(), "hello", nil)
+	require.NoError(t, err)
+
+	// The 60 tokens of the first request are counted
+	_, err = c.Chat(context.Background(), strings.Repeat("word ", 40), nil)
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr), "got %v", err)
+	assert.Equal(t, "daily_token_budget", limitErr.Limit)
+	assert.Equal(t, float64(60), limitErr.Used)
+}
+
+func TestChatLimitFallbackAndConfirm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/primary") {
+			t.Error("the primary provider must not be called over its limit")
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"from fallback"}}]}`))
+	}))
+	defer server.Close()
+
+	limits := types.Limits{MaxPromptTokens: 1}
+	primary := &types.ClientConfig{
+		Provider: "openai",
+		APIBase:  server.URL + "/primary",
+		APIKey:   "test",
+		Timeout:  10,
+		Limits:   limits,
+	}
+
+	_, err := New(primary).Chat(context.Background(), "a long message", nil)
+	var limitErr *LimitError
+	assert.True(t, errors.As(err, &limitErr), "refused without fallback or confirmation")
+
+	fallback := *primary
+	fallback.Provider = "deepseek"
+	fallback.APIBase = server.URL + "/fallback"
+	fallback.Limits = types.Limits{}
+	withFallback := *primary
+	withFallback.Fallback = &fallback
+	var notices []string
+	c := New(&withFallback)
+	c.SetNotify(func(message string) {
+		notices = append(notices, message)
+	})
+	resp, err := c.Chat(context.Background(), "a long message", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "from fallback", resp.Content)
+	require.Len(t, notices, 1)
+	assert.Contains(t, notices[0], "falling back to deepseek")
+
+	asked := false
+	SetLimitConfirm(func(err *LimitError) bool {
+		asked = true
+		return false
+	})
+	defer SetLimitConfirm(nil)
+	confirming := *primary
+	confirming.Limits.Confirm = true
+	_, err = New(&confirming).Chat(context.Background(), "a long message", nil)
+	assert.Error(t, err)
+	assert.True(t, asked)
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/llm"
+	"github.com/belingud/go-gptcomet/pkg/types"
+)
+
+// usageRecorder is an http.RoundTripper that keeps the body of the last successful
+// response, so the token usage can be read without changing every provider.
+// Streamed responses are passed through untouched.
+// It also records when the first response arrived.
+type usageRecorder struct {
+	base http.RoundTripper
+
+	mu        sync.Mutex
+	body      []byte
+	firstByte time.Time
+}
+
+// RoundTrip sends the request with the base transport and records the response body
+func (r *usageRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err == nil {
+		r.mu.Lock()
+		if r.firstByte.IsZero() {
+			r.firstByte = time.Now()
+		}
+		r.mu.Unlock()
+	}
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	// Streamed responses are read as they arrive, their usage is in the last chunk
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	r.mu.Lock()
+	r.body = data
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Usage returns the token usage reported in the last recorded response
+func (r *usageRecorder) Usage() *types.Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.body == nil {
+		return nil
+	}
+	return llm.ParseUsage(r.body)
+}
+
+// FirstByte returns the time between start and the first response, zero if none arrived
+func (r *usageRecorder) FirstByte(start time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.firstByte.IsZero() {
+		return 0
+	}
+	return r.firstByte.Sub(start)
+}
@@ -0,0 +1,100 @@
+// Package commitmsg turns the answers of providers into commit messages. The command
+// line, the server and the library all go through Finish, so a diff gives the same
+// message whichever way it is sent.
+package commitmsg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/committype"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/scope"
+)
+
+// Options are the settings applied to a generated commit message
+type Options struct {
+	// Style is the output style the message is validated against and formatted in
+	Style config.OutputStyleConfig
+	// Types are the allowed commit types
+	Types []committype.Type
+	// Scopes are enforced on the message, see StagedScopes
+	Scopes []string
+	// Translate translates the message to the output language, nil when it is English
+	Translate func(message string) (string, error)
+	// Warn receives the validation problems, which do not stop Finish
+	Warn func(err error)
+}
+
+// Finish validates message, translates it, then applies the scopes and the output style.
+// The message is validated before the translation, which may translate the type.
+func Finish(message string, opts Options) (string, error) {
+	message = strings.TrimSpace(message)
+	if err := Validate(opts.Style.Style, message, opts.Types); err != nil && opts.Warn != nil {
+		opts.Warn(err)
+	}
+	if opts.Translate != nil {
+		translated, err := opts.Translate(message)
+		if err != nil {
+			return "", err
+		}
+		message = strings.TrimSpace(translated)
+	}
+	return opts.Style.Format(scope.Apply(message, opts.Scopes)), nil
+}
+
+// Validate checks that message is written with one of types in the output style
+func Validate(style string, message string, types []committype.Type) error {
+	switch style {
+	case config.OutputGitmoji:
+		return committype.ValidateGitmoji(message, types)
+	case config.OutputPlain:
+		return nil
+	}
+	return committype.Validate(message, types)
+}
+
+// StagedScopes returns the sorted scopes of the files staged in the repository at
+// repoPath, see FileScopes
+func StagedScopes(cfgManager *config.Manager, vcs *git.GitVCS, repoPath string) ([]string, map[string][]string, error) {
+	files, err := vcs.GetStagedFiles(repoPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get staged files: %w", err)
+	}
+	return FileScopes(cfgManager, vcs, repoPath, files)
+}
+
+// FileScopes returns the sorted scopes of files from the "scopes" config, and the files
+// of each scope. files are relative to the repository root.
+func FileScopes(cfgManager *config.Manager, vcs *git.GitVCS, repoPath string, files []string) ([]string, map[string][]string, error) {
+	scopeConfig := cfgManager.GetScopeConfig()
+	rules := make([]scope.Rule, 0, len(scopeConfig.Map))
+	for pattern, name := range scopeConfig.Map {
+		rules = append(rules, scope.Rule{Pattern: pattern, Scope: name})
+	}
+
+	var roots []string
+	if scopeConfig.Auto {
+		pathspecs := make([]string, 0, 2*len(scope.ManifestFiles))
+		for _, manifest := range scope.ManifestFiles {
+			pathspecs = append(pathspecs, manifest, "*/"+manifest)
+		}
+		// Manifests are listed from the root, like files
+		root, err := vcs.GetRepoRoot(repoPath)
+		if err != nil || root == "" {
+			root = repoPath
+		}
+		manifests, err := vcs.GetTrackedFiles(root, pathspecs...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find package roots: %w", err)
+		}
+		roots = scope.Roots(manifests)
+	}
+	if len(rules) == 0 && len(roots) == 0 {
+		return nil, nil, nil
+	}
+
+	scopes, byScope := scope.NewMapper(rules, roots).Scopes(files)
+	return scopes, byScope, nil
+}
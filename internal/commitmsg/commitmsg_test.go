@@ -0,0 +1,66 @@
+package commitmsg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/committype"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinish(t *testing.T) {
+	types := committype.Defaults
+	gitmoji := config.OutputStyleConfig{Style: config.OutputGitmoji, Emoji: config.EmojiCode}
+
+	// The type is validated before the translation, scopes and style apply to the translation
+	var warnings []error
+	var translated string
+	message, err := Finish("  ✨ feat: add login\n", Options{
+		Style:  gitmoji,
+		Types:  types,
+		Scopes: []string{"auth"},
+		Translate: func(message string) (string, error) {
+			translated = message
+			return "✨ feat: ajoute la connexion", nil
+		},
+		Warn: func(err error) {
+			warnings = append(warnings, err)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "✨ feat: add login", translated)
+	assert.Equal(t, ":sparkles: feat(auth): ajoute la connexion", message)
+	assert.Empty(t, warnings)
+
+	// Validation problems are reported without stopping
+	message, err = Finish("Add login", Options{
+		Style: config.OutputStyleConfig{Style: config.OutputConventional},
+		Types: types,
+		Warn: func(err error) {
+			warnings = append(warnings, err)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Add login", message)
+	require.Len(t, warnings, 1)
+	var unknown *committype.UnknownTypeError
+	assert.ErrorAs(t, warnings[0], &unknown)
+
+	_, err = Finish("feat: add login", Options{
+		Translate: func(message string) (string, error) {
+			return "", errors.New("overloaded")
+		},
+	})
+	assert.EqualError(t, err, "overloaded")
+}
+
+func TestValidate(t *testing.T) {
+	types := committype.Defaults
+	assert.NoError(t, Validate(config.OutputConventional, "feat: add login", types))
+	assert.Error(t, Validate(config.OutputConventional, "Add login", types))
+	assert.NoError(t, Validate(config.OutputGitmoji, "✨ feat: add login", types))
+	assert.Error(t, Validate(config.OutputGitmoji, ":bug: feat: add login", types))
+	assert.NoError(t, Validate(config.OutputPlain, "Add login", types))
+}
@@ -0,0 +1,111 @@
+// Package committype holds the vocabulary of commit types generated messages are written with.
+package committype
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/gitmoji"
+)
+
+// Type is a commit type such as feat, with the description given to the model
+type Type struct {
+	Name        string
+	Description string
+	// Emoji is the gitmoji of the type, e.g. :sparkles:
+	Emoji string
+}
+
+// Defaults are the built-in commit types
+var Defaults = []Type{
+	{Name: "build", Description: "changes that affect the build system or external dependencies (example scopes: gulp, broccoli, npm)", Emoji: ":package:"},
+	{Name: "chore", Description: "updating libraries, copyrights or other setting, includes updating dependencies.", Emoji: ":wrench:"},
+	{Name: "ci", Description: "changes to our CI configuration files and scripts (example scopes: Travis, Circle, gitHub Actions)", Emoji: ":construction_worker:"},
+	{Name: "docs", Description: "non-code changes, such as fixing typos or adding new documentation", Emoji: ":memo:"},
+	{Name: "feat", Description: "a commit of the type feat introduces a new feature to the codebase", Emoji: ":sparkles:"},
+	{Name: "fix", Description: "a commit of the type fix patches a bug in your codebase", Emoji: ":bug:"},
+	{Name: "perf", Description: "a code change that improves performance", Emoji: ":zap:"},
+	{Name: "refactor", Description: "a code change that neither fixes a bug nor adds a feature", Emoji: ":recycle:"},
+	{Name: "revert", Description: "reverts a previous commit", Emoji: ":rewind:"},
+	{Name: "style", Description: "changes that do not affect the meaning of the code (white-space, formatting, missing semi-colons, etc)", Emoji: ":art:"},
+	{Name: "test", Description: "adding missing tests or correcting existing tests", Emoji: ":white_check_mark:"},
+}
+
+// subjectRe matches "type(scope)!: summary" subjects
+var subjectRe = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?!?:\s*\S`)
+
+// Names returns the names of types
+func Names(types []Type) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Find returns the type named name, ignoring case
+func Find(types []Type, name string) (Type, bool) {
+	for _, t := range types {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// Of returns the type of the first line of message written as "type(scope): summary",
+// empty if it has none
+func Of(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	match := subjectRe.FindStringSubmatch(strings.TrimSpace(subject))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// UnknownTypeError is returned when a message is not written with one of the known types
+type UnknownTypeError struct {
+	// Type is the type of the message, empty if it has none
+	Type  string
+	Known []string
+}
+
+// Error lists the known types
+func (e *UnknownTypeError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("commit message has no commit type, expected one of %s", strings.Join(e.Known, ", "))
+	}
+	return fmt.Sprintf("unknown commit type %q, expected one of %s", e.Type, strings.Join(e.Known, ", "))
+}
+
+// Validate returns an *UnknownTypeError if the subject of message does not start with one of types
+func Validate(message string, types []Type) error {
+	typ := Of(message)
+	if typ != "" {
+		if _, ok := Find(types, typ); ok {
+			return nil
+		}
+	}
+	return &UnknownTypeError{Type: typ, Known: Names(types)}
+}
+
+// ValidateGitmoji returns an error if the subject of message is not written as
+// "<gitmoji> type(scope): summary" with one of types and the gitmoji of that type.
+// Types without an emoji accept any gitmoji.
+func ValidateGitmoji(message string, types []Type) error {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	emoji, rest, _ := strings.Cut(strings.TrimSpace(subject), " ")
+	if !gitmoji.IsGitmoji(emoji) {
+		return fmt.Errorf("commit message does not start with a gitmoji")
+	}
+	if err := Validate(rest, types); err != nil {
+		return err
+	}
+	t, _ := Find(types, Of(rest))
+	if t.Emoji != "" && gitmoji.ToCode(t.Emoji) != gitmoji.ToCode(emoji) {
+		return fmt.Errorf("gitmoji %s does not match the commit type %s, expected %s", emoji, t.Name, t.Emoji)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package committype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, "feat", Of("feat(api)!: drop v1\n\n- remove routes"))
+	assert.Equal(t, "fix", Of("\n fix: round totals"))
+	assert.Equal(t, "", Of("Round totals"))
+	assert.Equal(t, "", Of("fix:"))
+}
+
+func TestValidate(t *testing.T) {
+	types := append(Defaults, Type{Name: "sec", Description: "security fixes"})
+	assert.NoError(t, Validate("sec: escape user input", types))
+	assert.NoError(t, Validate("Fix(core): handle nil", types))
+
+	err := Validate("deps: bump cobra", types)
+	var unknown *UnknownTypeError
+	if assert.ErrorAs(t, err, &unknown) {
+		assert.Equal(t, "deps", unknown.Type)
+	}
+	assert.Contains(t, err.Error(), "unknown commit type \"deps\", expected one of build, chore")
+	assert.EqualError(t, Validate("Bump cobra", []Type{{Name: "deps"}}), "commit message has no commit type, expected one of deps")
+}
+
+func TestValidateGitmoji(t *testing.T) {
+	types := append(Defaults, Type{Name: "sec"})
+	assert.NoError(t, ValidateGitmoji(":sparkles: feat(api): add login\n\n- add routes", types))
+	assert.NoError(t, ValidateGitmoji("♻ refactor: split parser", types))
+	assert.NoError(t, ValidateGitmoji(":lock: sec: escape input", types))
+
+	assert.EqualError(t, ValidateGitmoji("feat: add login", types), "commit message does not start with a gitmoji")
+	assert.EqualError(t, ValidateGitmoji(":bug: feat: add login", types), "gitmoji :bug: does not match the commit type feat, expected :sparkles:")
+	var unknown *UnknownTypeError
+	assert.ErrorAs(t, ValidateGitmoji("🐛 bugfix: handle nil", types), &unknown)
+}
@@ -2,36 +2,62 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/belingud/go-gptcomet/internal/committype"
+	"github.com/belingud/go-gptcomet/internal/gitmoji"
+	"github.com/belingud/go-gptcomet/internal/usage"
 	"github.com/belingud/go-gptcomet/pkg/config/defaults"
 	"github.com/belingud/go-gptcomet/pkg/types"
 
 	"gopkg.in/yaml.v3"
 )
 
-// Manager handles configuration management
+// output receives notices such as the discovered provider, nil to discard them
+var output io.Writer
+
+// SetOutput sets where notices such as the discovered provider are printed.
+// Nothing is printed by default, so the package can be used as a library.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// Manager handles configuration management.
+//
+// The configuration is made of layers, each overriding the previous one: the built-in
+// defaults, the global config file, the .gptcomet.yaml file at the repository root,
+// GPTCOMET_<SECTION>__<KEY> environment variables and the command line overrides.
+// Changes are only made to the global config file.
 type Manager struct {
-	config     map[string]interface{}
-	configPath string
+	// config is the merge of every layer
+	config map[string]interface{}
+	// global is the content of the global config file
+	global         map[string]interface{}
+	repo           map[string]interface{}
+	configPath     string
+	repoConfigPath string
 }
 
-// New creates a new configuration manager
+// New creates a new configuration manager, reading the repository config of the
+// repository holding the working directory
 func New(configPath string) (*Manager, error) {
-	if configPath == "" {
-		var err error
-		configPath, err = getConfigDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get config directory: %w", err)
-		}
-		configPath = configPath + "/gptcomet.yaml"
+	return NewForRepo(configPath, ".")
+}
+
+// NewForRepo creates a new configuration manager, reading the repository config of the
+// repository holding repoPath
+func NewForRepo(configPath, repoPath string) (*Manager, error) {
+	configPath, err := ResolvePath(configPath)
+	if err != nil {
+		return nil, err
 	}
 
 	manager := &Manager{
-		config:     make(map[string]interface{}),
+		global:     make(map[string]interface{}),
 		configPath: configPath,
 	}
 
@@ -48,15 +74,74 @@ func New(configPath string) (*Manager, error) {
 	} else {
 		// Initialize with default configuration
 		defaultConfig := defaultConfig()
-		manager.config = defaultConfig
+		manager.global = defaultConfig
 		if err := manager.save(); err != nil {
 			return nil, fmt.Errorf("failed to save default config: %w", err)
 		}
 	}
 
+	if err := manager.loadRepo(repoPath); err != nil {
+		return nil, err
+	}
 	return manager, nil
 }
 
+// Open reads the config file at configPath without creating it, and the repository config
+// of the repository holding repoPath unless repoPath is empty. The error wraps
+// fs.ErrNotExist when the config file does not exist.
+func Open(configPath, repoPath string) (*Manager, error) {
+	configPath, err := ResolvePath(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, fmt.Errorf("failed to open config: %w", err)
+	}
+
+	manager := &Manager{
+		global:     make(map[string]interface{}),
+		configPath: configPath,
+	}
+	if err := manager.load(); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if repoPath == "" {
+		if err := manager.merge(); err != nil {
+			return nil, err
+		}
+		return manager, nil
+	}
+	if err := manager.loadRepo(repoPath); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// loadRepo reads the repository config of the repository holding repoPath, if any,
+// and merges the layers
+func (m *Manager) loadRepo(repoPath string) error {
+	if path := findRepoConfig(repoPath); path != "" {
+		repo, err := loadRepoConfig(path)
+		if err != nil {
+			return err
+		}
+		m.repo = repo
+		m.repoConfigPath = path
+	}
+	return m.merge()
+}
+
+// merge computes the configuration from the layers
+func (m *Manager) merge() error {
+	defaults, err := defaultLayer()
+	if err != nil {
+		return err
+	}
+	config := mergeLayers(defaults, m.global, m.repo)
+	m.config = mergeLayers(config, envLayer(config, os.Environ()), overrides)
+	return nil
+}
+
 // GetClientConfig retrieves the client configuration
 func (m *Manager) GetClientConfig() (*types.ClientConfig, error) {
 	provider, ok := m.config["provider"].(string)
@@ -64,6 +149,26 @@ func (m *Manager) GetClientConfig() (*types.ClientConfig, error) {
 		return nil, fmt.Errorf("provider not set")
 	}
 
+	clientConfig, err := m.GetProviderClientConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	if output != nil {
+		fmt.Fprintf(output, "Discovered provider: %s, model: %s\n", provider, clientConfig.Model)
+	}
+	return clientConfig, nil
+}
+
+// GetProviderClientConfig retrieves the client configuration of the given provider,
+// which does not need to be the active one
+func (m *Manager) GetProviderClientConfig(provider string) (*types.ClientConfig, error) {
+	return m.providerClientConfig(provider, map[string]bool{})
+}
+
+// providerClientConfig builds the client config of provider, including its fallback chain.
+// visited holds the providers already in the chain to stop fallback cycles.
+func (m *Manager) providerClientConfig(provider string, visited map[string]bool) (*types.ClientConfig, error) {
+	visited[provider] = true
 	providerConfig, ok := m.config[provider].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf("provider config not found: %s", provider)
@@ -108,8 +213,6 @@ func (m *Manager) GetClientConfig() (*types.ClientConfig, error) {
 	if m, ok := providerConfig["frequency_penalty"].(float64); ok {
 		frequencyPenalty = m
 	}
-	fmt.Printf("Discovered provider: %s, model: %s\n", provider, model)
-
 	clientConfig := &types.ClientConfig{
 		APIBase:          apiBase,
 		APIKey:           apiKey,
@@ -133,9 +236,73 @@ func (m *Manager) GetClientConfig() (*types.ClientConfig, error) {
 	if completionPath, ok := providerConfig["completion_path"].(string); ok {
 		clientConfig.CompletionPath = completionPath
 	}
+
+	limits, err := m.getLimits(providerConfig, model)
+	if err != nil {
+		return nil, err
+	}
+	clientConfig.Limits = limits
+	if fallback, ok := providerConfig["fallback"].(string); ok && fallback != "" && !visited[fallback] {
+		fallbackConfig, err := m.providerClientConfig(fallback, visited)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fallback provider of %s: %w", provider, err)
+		}
+		clientConfig.Fallback = fallbackConfig
+	}
 	return clientConfig, nil
 }
 
+// getLimits reads the token and spending limits of a provider section
+func (m *Manager) getLimits(providerConfig map[string]interface{}, model string) (types.Limits, error) {
+	limits := types.Limits{Confirm: true}
+	if v, ok := toInt(providerConfig["max_prompt_tokens"]); ok {
+		limits.MaxPromptTokens = v
+	}
+	if v, ok := toInt(providerConfig["daily_token_budget"]); ok {
+		limits.DailyTokenBudget = v
+	}
+	if v, ok := toInt(providerConfig["monthly_token_budget"]); ok {
+		limits.MonthlyTokenBudget = v
+	}
+	if v, ok := toFloat(providerConfig["daily_cost_budget"]); ok {
+		limits.DailyCostBudget = v
+	}
+	if v, ok := toFloat(providerConfig["monthly_cost_budget"]); ok {
+		limits.MonthlyCostBudget = v
+	}
+	if price, ok := usage.LookupPrice(m.GetUsagePrices(), model); ok {
+		limits.PromptPrice = price.Prompt
+		limits.CompletionPrice = price.Completion
+	}
+	if value, ok := m.Get("budget.on_exceed"); ok {
+		onExceed, err := parseOnExceed(value)
+		if err != nil {
+			return limits, err
+		}
+		limits.Confirm = onExceed == BudgetConfirm
+	}
+	if limits.HasBudget() {
+		// The spending is recorded next to the config file, like the usage command reads it
+		limits.Ledger = filepath.Join(filepath.Dir(m.configPath), usage.LedgerFile)
+	}
+	return limits, nil
+}
+
+const (
+	// BudgetConfirm asks before sending a request over a limit
+	BudgetConfirm = "confirm"
+	// BudgetRefuse refuses requests over a limit
+	BudgetRefuse = "refuse"
+)
+
+// parseOnExceed returns the budget.on_exceed value, BudgetConfirm or BudgetRefuse
+func parseOnExceed(value interface{}) (string, error) {
+	if str, ok := value.(string); ok && (str == BudgetConfirm || str == BudgetRefuse) {
+		return str, nil
+	}
+	return "", fmt.Errorf("invalid budget.on_exceed: %v, expected %s or %s", value, BudgetConfirm, BudgetRefuse)
+}
+
 // SetProvider sets the provider configuration
 func (m *Manager) SetProvider(provider, apiKey, apiBase, model string) error {
 	if apiBase == "" {
@@ -145,19 +312,19 @@ func (m *Manager) SetProvider(provider, apiKey, apiBase, model string) error {
 		model = types.DefaultModel
 	}
 
-	m.config[provider] = map[string]interface{}{
+	m.global[provider] = map[string]interface{}{
 		"api_key":  apiKey,
 		"api_base": apiBase,
 		"model":    model,
 	}
-	m.config["provider"] = provider
+	m.global["provider"] = provider
 
 	return m.save()
 }
 
 // Get retrieves a configuration value
 func (m *Manager) Get(key string) (interface{}, bool) {
-	return m.getNestedValue(strings.Split(key, "."))
+	return getNestedValue(m.config, strings.Split(key, "."))
 }
 
 // Set sets a configuration value
@@ -169,9 +336,28 @@ func (m *Manager) Set(key string, value interface{}) error {
 			}
 		}
 	}
+	if key == "scopes.multiple" && value != ScopesJoin && value != ScopesSplit {
+		return fmt.Errorf("invalid scopes.multiple: %v, expected %s or %s", value, ScopesJoin, ScopesSplit)
+	}
+	if key == "output.style" && !containsValue(OutputStyles, value) {
+		return fmt.Errorf("invalid output.style: %v, expected one of %s", value, strings.Join(OutputStyles, ", "))
+	}
+	if key == "budget.on_exceed" {
+		if _, err := parseOnExceed(value); err != nil {
+			return err
+		}
+	}
+	if key == "output.emoji" && value != EmojiCode && value != EmojiUnicode {
+		return fmt.Errorf("invalid output.emoji: %v, expected %s or %s", value, EmojiCode, EmojiUnicode)
+	}
+	if key == CommitTypesKey {
+		if err := validateCommitTypes(value); err != nil {
+			return err
+		}
+	}
 
 	keys := strings.Split(key, ".")
-	m.setNestedValue(keys, value)
+	setNestedValue(m.global, keys, value)
 	return m.save()
 }
 
@@ -194,11 +380,11 @@ func (m *Manager) Reset(promptOnly bool) error {
 		// Get default prompt config
 		defaultCfg := defaultConfig()
 		if promptConfig, ok := defaultCfg["prompt"].(map[string]interface{}); ok {
-			m.config["prompt"] = promptConfig
+			m.global["prompt"] = promptConfig
 		}
 	} else {
 		// Reset all config
-		m.config = defaultConfig()
+		m.global = defaultConfig()
 	}
 	return m.save()
 }
@@ -209,7 +395,7 @@ func (m *Manager) Remove(key string, value string) error {
 	if value == "" {
 		// If no value is provided, remove the entire key
 		lastKey := keys[len(keys)-1]
-		parent, ok := m.getNestedValue(keys[:len(keys)-1])
+		parent, ok := getNestedValue(m.global, keys[:len(keys)-1])
 		if !ok {
 			return nil
 		}
@@ -220,16 +406,11 @@ func (m *Manager) Remove(key string, value string) error {
 		return m.save()
 	}
 
-	// If value is provided, try to remove it from a list
-	current, ok := m.getNestedValue(keys)
-	if !ok {
-		return nil
-	}
-
-	// Check if the current value is a list
-	list, ok := current.([]interface{})
-	if !ok {
-		return fmt.Errorf("value at key '%s' is not a list", key)
+	// If value is provided, try to remove it from a list, which may be inherited
+	// from the defaults
+	list, err := m.globalList(key, keys)
+	if err != nil {
+		return err
 	}
 
 	// Find and remove the value from the list
@@ -237,25 +418,68 @@ func (m *Manager) Remove(key string, value string) error {
 	for _, item := range list {
 		if str, ok := item.(string); ok && str != value {
 			newList = append(newList, item)
-		} else if !ok {
+		} else if entry, isMap := item.(map[string]interface{}); isMap && entry["name"] != value {
+			// Named entries such as commit types are removed by name
+			newList = append(newList, item)
+		} else if !ok && !isMap {
 			newList = append(newList, item)
 		}
 	}
+	if len(newList) == len(list) {
+		return fmt.Errorf("'%s' is not in '%s' of the global config", value, key)
+	}
 
 	return m.Set(key, newList)
 }
 
+// globalList returns the list at key as set by the global config, with the items it
+// inherits from the defaults expanded. A list only set by the defaults is returned as is.
+func (m *Manager) globalList(key string, keys []string) ([]interface{}, error) {
+	defaults, err := defaultLayer()
+	if err != nil {
+		return nil, err
+	}
+	inherited, _ := getNestedValue(defaults, keys)
+	current, ok := getNestedValue(m.global, keys)
+	if !ok {
+		if _, isList := inherited.([]interface{}); !isList {
+			return nil, fmt.Errorf("'%s' is not set in the global config %s", key, m.configPath)
+		}
+		current = []interface{}{InheritMarker}
+	}
+	list, ok := current.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at key '%s' is not a list", key)
+	}
+	return mergeValues(inherited, list).([]interface{}), nil
+}
+
 // GetPath returns the configuration file path
 func (m *Manager) GetPath() string {
 	return m.configPath
 }
 
+// GetRepoConfigPath returns the path of the repository config, empty if there is none
+func (m *Manager) GetRepoConfigPath() string {
+	return m.repoConfigPath
+}
+
 // Append appends a value to a list configuration
 func (m *Manager) Append(key string, value interface{}) error {
 	keys := strings.Split(key, ".")
-	current, ok := m.getNestedValue(keys)
+	current, ok := getNestedValue(m.global, keys)
 	if !ok {
-		// If the key doesn't exist, create a new list
+		// If the key doesn't exist, create a new list, extending the list of the
+		// defaults if there is one
+		defaults, err := defaultLayer()
+		if err != nil {
+			return err
+		}
+		if inherited, ok := getNestedValue(defaults, keys); ok {
+			if _, ok := inherited.([]interface{}); ok {
+				return m.Set(key, []interface{}{InheritMarker, value})
+			}
+		}
 		return m.Set(key, []interface{}{value})
 	}
 
@@ -270,9 +494,9 @@ func (m *Manager) Append(key string, value interface{}) error {
 	return m.Set(key, list)
 }
 
-// getNestedValue retrieves a nested configuration value
-func (m *Manager) getNestedValue(keys []string) (interface{}, bool) {
-	current := interface{}(m.config)
+// getNestedValue retrieves a nested value of config
+func getNestedValue(config map[string]interface{}, keys []string) (interface{}, bool) {
+	current := interface{}(config)
 	for _, key := range keys {
 		currentMap, ok := current.(map[string]interface{})
 		if !ok {
@@ -286,9 +510,9 @@ func (m *Manager) getNestedValue(keys []string) (interface{}, bool) {
 	return current, true
 }
 
-// setNestedValue sets a nested configuration value
-func (m *Manager) setNestedValue(keys []string, value interface{}) {
-	current := m.config
+// setNestedValue sets a nested value of config
+func setNestedValue(config map[string]interface{}, keys []string, value interface{}) {
+	current := config
 	for _, key := range keys[:len(keys)-1] {
 		next, ok := current[key]
 		if !ok {
@@ -313,16 +537,16 @@ func (m *Manager) load() error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	if err := yaml.Unmarshal(data, &m.config); err != nil {
+	if err := yaml.Unmarshal(data, &m.global); err != nil {
 		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	return nil
 }
 
-// save writes the configuration to file
+// save writes the global configuration to file and merges the layers again
 func (m *Manager) save() error {
-	data, err := yaml.Marshal(m.config)
+	data, err := yaml.Marshal(m.global)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -331,7 +555,19 @@ func (m *Manager) save() error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
-	return nil
+	return m.merge()
+}
+
+// ResolvePath returns configPath, or the default config file path if configPath is empty
+func ResolvePath(configPath string) (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return configDir + "/gptcomet.yaml", nil
 }
 
 // getConfigDir returns the configuration directory path
@@ -464,7 +700,7 @@ func IsValidLanguage(lang string) bool {
 // GetSupportedKeys returns a list of supported configuration keys
 func (m *Manager) GetSupportedKeys() []string {
 	// Get current provider
-	provider, _ := m.getNestedValue([]string{"provider"})
+	provider, _ := m.Get("provider")
 	providerStr, ok := provider.(string)
 	if !ok || providerStr == "" {
 		providerStr = "openai"
@@ -481,6 +717,8 @@ func (m *Manager) GetSupportedKeys() []string {
 	outputKeys := []string{
 		"lang",
 		"rich_template",
+		"style",
+		"emoji",
 	}
 	for _, key := range outputKeys {
 		keys["output."+key] = true
@@ -508,20 +746,97 @@ func (m *Manager) GetSupportedKeys() []string {
 		"extra_headers",
 		"completion_path",
 		"answer_path",
+		"max_prompt_tokens",
+		"daily_token_budget",
+		"monthly_token_budget",
+		"daily_cost_budget",
+		"monthly_cost_budget",
+		"fallback",
 	}
 	for _, key := range providerKeys {
 		keys["<provider>."+key] = true
 	}
 
+	// Branch keys
+	branchKeys := []string{
+		"prefixes",
+		"ticket_pattern",
+		"max_length",
+	}
+	for _, key := range branchKeys {
+		keys["branch."+key] = true
+	}
+
+	// Budget keys
+	keys["budget.on_exceed"] = true
+
+	// Commit type vocabulary
+	keys[CommitTypesKey] = true
+
+	// Related history keys
+	keys["related_history.enabled"] = true
+	keys["related_history.commits"] = true
+
+	// Scope keys
+	scopeKeys := []string{
+		"map.<pattern>",
+		"auto",
+		"multiple",
+	}
+	for _, key := range scopeKeys {
+		keys["scopes."+key] = true
+	}
+
+	// Style keys
+	styleKeys := []string{
+		"enabled",
+		"samples",
+		"examples",
+	}
+	for _, key := range styleKeys {
+		keys["style."+key] = true
+	}
+
+	// Usage keys
+	usageKeys := []string{
+		"prices.<model>.prompt",
+		"prices.<model>.completion",
+	}
+	for _, key := range usageKeys {
+		keys["usage."+key] = true
+	}
+
 	// Prompt keys
 	promptKeys := []string{
+		"ask",
+		"branch",
 		"brief_commit_message",
+		"eval_judge",
+		"explain",
+		"resolve",
+		"review",
 		"rich_commit_message",
+		"standup",
+		"stash",
 		"translation",
+		"why",
 	}
 	for _, key := range promptKeys {
 		keys["prompt."+key] = true
 	}
+	keys["prompt.profile"] = true
+
+	// Prompt profile keys
+	profileKeys := append([]string{
+		"model",
+		"max_tokens",
+		"temperature",
+		"top_p",
+		"frequency_penalty",
+	}, profilePrompts...)
+	for _, key := range profileKeys {
+		keys["prompt.profiles.<name>."+key] = true
+	}
 
 	// Convert map to sorted slice
 	result := make([]string, 0, len(keys))
@@ -536,48 +851,133 @@ func (m *Manager) GetSupportedKeys() []string {
 	return result
 }
 
-// GetPrompt retrieves the prompt configuration
-func (m *Manager) GetPrompt(isRich bool) string {
-	promptConfig, ok := m.config["prompt"].(map[string]interface{})
+// profilePrompts are the prompts a profile can override
+var profilePrompts = []string{"brief_commit_message", "rich_commit_message", "translation"}
+
+// PromptProfile is a named set of prompts and model parameters, read from prompt.profiles.<name>
+type PromptProfile struct {
+	Name string
+	// Prompts are the prompts set by the profile, by name, e.g. brief_commit_message
+	Prompts map[string]string
+	// Model and the parameters below override the provider settings when set
+	Model            string
+	MaxTokens        int
+	Temperature      *float64
+	TopP             *float64
+	FrequencyPenalty *float64
+}
+
+// Apply overrides the model parameters of clientConfig with those set by the profile
+func (p *PromptProfile) Apply(clientConfig *types.ClientConfig) {
+	if p == nil {
+		return
+	}
+	if p.Model != "" {
+		clientConfig.Model = p.Model
+	}
+	if p.MaxTokens > 0 {
+		clientConfig.MaxTokens = p.MaxTokens
+	}
+	if p.Temperature != nil {
+		clientConfig.Temperature = *p.Temperature
+	}
+	if p.TopP != nil {
+		clientConfig.TopP = *p.TopP
+	}
+	if p.FrequencyPenalty != nil {
+		clientConfig.FrequencyPenalty = *p.FrequencyPenalty
+	}
+}
+
+// GetProfileNames returns the names of the profiles in prompt.profiles, sorted
+func (m *Manager) GetProfileNames() []string {
+	profiles, _ := m.Get("prompt.profiles")
+	profilesMap, _ := profiles.(map[string]interface{})
+	names := make([]string, 0, len(profilesMap))
+	for name := range profilesMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile returns the prompt profile stored under prompt.profiles.<name>
+func (m *Manager) GetProfile(name string) (*PromptProfile, error) {
+	value, ok := m.Get("prompt.profiles." + name)
 	if !ok {
-		// return default prompt if not set in config
-		if isRich {
-			return defaults.PromptDefaults["rich_commit_message"]
-		} else {
-			return defaults.PromptDefaults["brief_commit_message"]
+		names := m.GetProfileNames()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("prompt profile not found: %s, no profiles are configured in prompt.profiles", name)
 		}
+		return nil, fmt.Errorf("prompt profile not found: %s, available profiles: %s", name, strings.Join(names, ", "))
+	}
+	section, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("prompt.profiles.%s is not a map: %v", name, value)
 	}
 
-	if isRich {
-		if rich, ok := promptConfig["rich_commit_message"].(string); ok {
-			return rich
-		}
-	} else {
-		if brief, ok := promptConfig["brief_commit_message"].(string); ok {
-			return brief
+	profile := &PromptProfile{Name: name, Prompts: make(map[string]string)}
+	for _, key := range profilePrompts {
+		if prompt, ok := section[key].(string); ok {
+			profile.Prompts[key] = prompt
 		}
 	}
+	if model, ok := section["model"].(string); ok {
+		profile.Model = model
+	}
+	if maxTokens, ok := toInt(section["max_tokens"]); ok {
+		profile.MaxTokens = maxTokens
+	}
+	if temperature, ok := toFloat(section["temperature"]); ok {
+		profile.Temperature = &temperature
+	}
+	if topP, ok := toFloat(section["top_p"]); ok {
+		profile.TopP = &topP
+	}
+	if frequencyPenalty, ok := toFloat(section["frequency_penalty"]); ok {
+		profile.FrequencyPenalty = &frequencyPenalty
+	}
+	return profile, nil
+}
 
-	// return default prompt if not set in config
+// GetPrompt retrieves the commit message prompt of profile, falling back to
+// prompt.<name> and the built-in default for the prompts the profile does not set.
+// profile may be nil.
+func (m *Manager) GetPrompt(profile *PromptProfile, isRich bool) string {
 	if isRich {
-		return defaults.PromptDefaults["rich_commit_message"]
-	} else {
-		return defaults.PromptDefaults["brief_commit_message"]
+		return m.getProfilePrompt(profile, "rich_commit_message")
 	}
+	return m.getProfilePrompt(profile, "brief_commit_message")
 }
 
-// GetTranslationPrompt retrieves the translation prompt
-func (m *Manager) GetTranslationPrompt() string {
+// GetTranslationPrompt retrieves the translation prompt of profile, which may be nil
+func (m *Manager) GetTranslationPrompt(profile *PromptProfile) string {
+	return m.getProfilePrompt(profile, "translation")
+}
+
+// getProfilePrompt returns the prompt name of profile, or the configured prompt
+func (m *Manager) getProfilePrompt(profile *PromptProfile, name string) string {
+	if profile != nil {
+		if prompt, ok := profile.Prompts[name]; ok {
+			return prompt
+		}
+	}
+	return m.GetNamedPrompt(name)
+}
+
+// GetNamedPrompt retrieves the prompt stored under prompt.<name>,
+// falling back to the built-in default when it is not set in config
+func (m *Manager) GetNamedPrompt(name string) string {
 	promptConfig, ok := m.config["prompt"].(map[string]interface{})
 	if !ok {
 		// return default prompt if not set in config
-		return defaults.PromptDefaults["translation"]
+		return defaults.PromptDefaults[name]
 	}
-	if translation, ok := promptConfig["translation"].(string); ok {
-		return translation
+	if prompt, ok := promptConfig[name].(string); ok {
+		return prompt
 	}
 	// return default prompt if not set in config
-	return defaults.PromptDefaults["translation"]
+	return defaults.PromptDefaults[name]
 }
 
 // MaskAPIKey masks an API key by showing only the first few characters and replacing the rest with asterisks
@@ -674,3 +1074,385 @@ func (m *Manager) UpdateProviderConfig(provider string, configs map[string]strin
 
 	return nil
 }
+
+// DefaultBranchPrefixes maps the built-in commit types to the branch name prefix used
+// for them. Other commit types are prefixed with their name.
+var DefaultBranchPrefixes = map[string]string{
+	"build":    "build/",
+	"chore":    "chore/",
+	"ci":       "ci/",
+	"docs":     "docs/",
+	"feat":     "feature/",
+	"fix":      "fix/",
+	"perf":     "perf/",
+	"refactor": "refactor/",
+	"style":    "style/",
+	"test":     "test/",
+}
+
+const (
+	// DefaultBranchMaxLength is the default maximum length of generated branch names
+	DefaultBranchMaxLength = 50
+	// DefaultTicketPattern matches ticket keys such as PROJ-123
+	DefaultTicketPattern = `[A-Z][A-Z0-9]+-[0-9]+`
+)
+
+// BranchConfig holds the naming conventions for generated branch names
+type BranchConfig struct {
+	// Prefixes maps a change type to its branch prefix, e.g. feat -> feature/
+	Prefixes map[string]string
+	// TicketPattern is a regular expression used to find a ticket key in the description
+	TicketPattern string
+	// MaxLength is the maximum length of the branch name
+	MaxLength int
+}
+
+// GetBranchConfig returns the branch naming conventions from the "branch" section,
+// falling back to the defaults for missing keys. Without branch.prefixes every commit
+// type has a prefix, see DefaultBranchPrefixes.
+func (m *Manager) GetBranchConfig() BranchConfig {
+	cfg := BranchConfig{
+		Prefixes:      make(map[string]string),
+		TicketPattern: DefaultTicketPattern,
+		MaxLength:     DefaultBranchMaxLength,
+	}
+	for _, t := range m.GetCommitTypes() {
+		prefix, ok := DefaultBranchPrefixes[t.Name]
+		if !ok {
+			prefix = t.Name + "/"
+		}
+		cfg.Prefixes[t.Name] = prefix
+	}
+
+	if value, ok := m.Get("branch.prefixes"); ok {
+		if prefixes, ok := value.(map[string]interface{}); ok {
+			cfg.Prefixes = make(map[string]string, len(prefixes))
+			for k, v := range prefixes {
+				if str, ok := v.(string); ok {
+					cfg.Prefixes[k] = str
+				}
+			}
+		}
+	}
+	if value, ok := m.Get("branch.ticket_pattern"); ok {
+		if pattern, ok := value.(string); ok {
+			cfg.TicketPattern = pattern
+		}
+	}
+	if value, ok := m.Get("branch.max_length"); ok {
+		if maxLength, ok := toInt(value); ok && maxLength > 0 {
+			cfg.MaxLength = maxLength
+		}
+	}
+	return cfg
+}
+
+const (
+	// DefaultStyleSamples is the default number of commits the style is learned from
+	DefaultStyleSamples = 100
+	// DefaultStyleExamples is the default number of example messages given to prompts
+	DefaultStyleExamples = 5
+)
+
+// StyleConfig holds the settings of learning the commit style of repositories
+type StyleConfig struct {
+	// Enabled adds the learned style to the commit message prompts
+	Enabled bool
+	// Samples is the number of recent commits the style is learned from
+	Samples int
+	// Examples is the number of example messages given to prompts
+	Examples int
+}
+
+// GetStyleConfig returns the style learning settings from the "style" section,
+// falling back to the defaults for missing keys
+func (m *Manager) GetStyleConfig() StyleConfig {
+	cfg := StyleConfig{
+		Enabled:  true,
+		Samples:  DefaultStyleSamples,
+		Examples: DefaultStyleExamples,
+	}
+	if value, ok := m.Get("style.enabled"); ok {
+		if enabled, ok := value.(bool); ok {
+			cfg.Enabled = enabled
+		}
+	}
+	if value, ok := m.Get("style.samples"); ok {
+		if samples, ok := toInt(value); ok && samples > 0 {
+			cfg.Samples = samples
+		}
+	}
+	if value, ok := m.Get("style.examples"); ok {
+		if examples, ok := toInt(value); ok && examples >= 0 {
+			cfg.Examples = examples
+		}
+	}
+	return cfg
+}
+
+const (
+	// ScopesJoin joins the scopes of a commit spanning several scopes with commas
+	ScopesJoin = "join"
+	// ScopesSplit also suggests splitting a commit spanning several scopes
+	ScopesSplit = "split"
+)
+
+// ScopeConfig holds the settings of the scopes derived from the paths of the staged files
+type ScopeConfig struct {
+	// Map maps glob patterns such as "services/billing/**" to a scope
+	Map map[string]string
+	// Auto names the directories holding a go.mod or package.json after themselves
+	Auto bool
+	// Multiple is what to do when the staged files span several scopes, ScopesJoin or ScopesSplit
+	Multiple string
+}
+
+// GetScopeConfig returns the scope settings from the "scopes" section,
+// falling back to the defaults for missing keys
+func (m *Manager) GetScopeConfig() ScopeConfig {
+	cfg := ScopeConfig{Map: map[string]string{}, Multiple: ScopesJoin}
+	if value, ok := m.Get("scopes.map"); ok {
+		if patterns, ok := value.(map[string]interface{}); ok {
+			for pattern, v := range patterns {
+				if scope, ok := v.(string); ok {
+					cfg.Map[pattern] = scope
+				}
+			}
+		}
+	}
+	if value, ok := m.Get("scopes.auto"); ok {
+		if auto, ok := value.(bool); ok {
+			cfg.Auto = auto
+		}
+	}
+	if value, ok := m.Get("scopes.multiple"); ok {
+		if multiple, ok := value.(string); ok && (multiple == ScopesJoin || multiple == ScopesSplit) {
+			cfg.Multiple = multiple
+		}
+	}
+	return cfg
+}
+
+// DefaultRelatedCommits is the default number of commits listed per staged file in the related history
+const DefaultRelatedCommits = 3
+
+// RelatedHistoryConfig holds the settings of the related history given to commit prompts
+type RelatedHistoryConfig struct {
+	// Enabled adds the last commits touching each staged file to the commit message prompts
+	Enabled bool
+	// Commits is the number of commits listed per file
+	Commits int
+}
+
+// GetRelatedHistoryConfig returns the related history settings from the "related_history"
+// section, falling back to the defaults for missing keys
+func (m *Manager) GetRelatedHistoryConfig() RelatedHistoryConfig {
+	cfg := RelatedHistoryConfig{Commits: DefaultRelatedCommits}
+	if value, ok := m.Get("related_history.enabled"); ok {
+		if enabled, ok := value.(bool); ok {
+			cfg.Enabled = enabled
+		}
+	}
+	if value, ok := m.Get("related_history.commits"); ok {
+		if commits, ok := toInt(value); ok && commits > 0 {
+			cfg.Commits = commits
+		}
+	}
+	return cfg
+}
+
+const (
+	// OutputConventional writes messages as "type(scope): summary"
+	OutputConventional = "conventional"
+	// OutputGitmoji writes messages as "<gitmoji> type(scope): summary"
+	OutputGitmoji = "gitmoji"
+	// OutputPlain writes messages without commit type
+	OutputPlain = "plain"
+
+	// EmojiCode writes gitmojis as codes such as :sparkles:
+	EmojiCode = "code"
+	// EmojiUnicode writes gitmojis as unicode characters
+	EmojiUnicode = "unicode"
+)
+
+// OutputStyles are the supported values of output.style
+var OutputStyles = []string{OutputConventional, OutputGitmoji, OutputPlain}
+
+// OutputStyleConfig holds the format of generated messages
+type OutputStyleConfig struct {
+	// Style is OutputConventional, OutputGitmoji or OutputPlain
+	Style string
+	// Emoji is how gitmojis are written, EmojiCode or EmojiUnicode
+	Emoji string
+}
+
+// GetOutputStyle returns the message format from output.style and output.emoji,
+// falling back to the defaults for missing keys
+func (m *Manager) GetOutputStyle() OutputStyleConfig {
+	cfg := OutputStyleConfig{Style: OutputConventional, Emoji: EmojiCode}
+	if value, ok := m.Get("output.style"); ok && containsValue(OutputStyles, value) {
+		cfg.Style = value.(string)
+	}
+	if value, ok := m.Get("output.emoji"); ok && (value == EmojiCode || value == EmojiUnicode) {
+		cfg.Emoji = value.(string)
+	}
+	return cfg
+}
+
+// Format writes the gitmojis of a gitmoji style message in the configured form
+func (s OutputStyleConfig) Format(message string) string {
+	if s.Style != OutputGitmoji {
+		return message
+	}
+	if s.Emoji == EmojiUnicode {
+		return gitmoji.ToUnicode(message)
+	}
+	return gitmoji.ToCode(message)
+}
+
+// containsValue reports whether value is one of values
+func containsValue(values []string, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CommitTypesKey is the key of the commit type vocabulary
+const CommitTypesKey = "commit_types"
+
+// GetCommitTypes returns the commit type vocabulary from the "commit_types" list. Items are
+// maps with a name, a description and an optional emoji, or bare names. An item replaces
+// the earlier item of the same name, and the built-in types are used when no item is valid.
+func (m *Manager) GetCommitTypes() []committype.Type {
+	value, _ := m.Get(CommitTypesKey)
+	items, _ := value.([]interface{})
+	var types []committype.Type
+	index := make(map[string]int)
+	for _, item := range items {
+		t, ok := parseCommitType(item)
+		if !ok {
+			continue
+		}
+		if i, ok := index[t.Name]; ok {
+			types[i] = t
+			continue
+		}
+		index[t.Name] = len(types)
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return append([]committype.Type(nil), committype.Defaults...)
+	}
+	return types
+}
+
+// parseCommitType reads an item of the commit_types list
+func parseCommitType(item interface{}) (committype.Type, bool) {
+	switch v := item.(type) {
+	case string:
+		if v == "" || v == InheritMarker {
+			return committype.Type{}, false
+		}
+		return committype.Type{Name: v}, true
+	case map[string]interface{}:
+		t := committype.Type{}
+		t.Name, _ = v["name"].(string)
+		t.Description, _ = v["description"].(string)
+		t.Emoji, _ = v["emoji"].(string)
+		return t, t.Name != ""
+	}
+	return committype.Type{}, false
+}
+
+// validateCommitTypes checks that value is a list of commit types
+func validateCommitTypes(value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("invalid %s: expected a list of commit types", CommitTypesKey)
+	}
+	for _, item := range items {
+		if item == InheritMarker {
+			continue
+		}
+		if _, ok := parseCommitType(item); !ok {
+			return fmt.Errorf("invalid commit type %v: expected a name or a map with a name, a description and an optional emoji", item)
+		}
+	}
+	return nil
+}
+
+// defaultCommitTypes returns the built-in commit types as a config list
+func defaultCommitTypes() []interface{} {
+	items := make([]interface{}, 0, len(committype.Defaults))
+	for _, t := range committype.Defaults {
+		items = append(items, map[string]interface{}{
+			"name":        t.Name,
+			"description": t.Description,
+			"emoji":       t.Emoji,
+		})
+	}
+	return items
+}
+
+// GetUsagePrices returns the price table used to estimate the cost of the usage ledger:
+// the built-in prices overridden by usage.prices.<model>.prompt and .completion
+func (m *Manager) GetUsagePrices() map[string]usage.Price {
+	prices := make(map[string]usage.Price, len(usage.DefaultPrices))
+	for model, price := range usage.DefaultPrices {
+		prices[model] = price
+	}
+
+	value, ok := m.Get("usage.prices")
+	if !ok {
+		return prices
+	}
+	models, ok := value.(map[string]interface{})
+	if !ok {
+		return prices
+	}
+	for model, v := range models {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		price := prices[model]
+		if prompt, ok := toFloat(entry["prompt"]); ok {
+			price.Prompt = prompt
+		}
+		if completion, ok := toFloat(entry["completion"]); ok {
+			price.Completion = completion
+		}
+		prices[model] = price
+	}
+	return prices
+}
+
+// toFloat converts a numeric config value to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// toInt converts a numeric config value to int, yaml decodes integers
+// as int while json decodes them as float64
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
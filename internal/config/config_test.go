@@ -1,9 +1,14 @@
 package config
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/belingud/go-gptcomet/internal/committype"
 	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/belingud/go-gptcomet/internal/usage"
+	"github.com/belingud/go-gptcomet/pkg/config/defaults"
+	"github.com/belingud/go-gptcomet/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -113,3 +118,219 @@ func TestConfig_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBranchConfig(t *testing.T) {
+	configFile, cleanup := testutils.TestConfig(t, `
+branch:
+  prefixes:
+    feat: feat/
+    sec: security/
+  max_length: 40
+`)
+	defer cleanup()
+
+	cfg, err := New(configFile)
+	require.NoError(t, err)
+
+	branchConfig := cfg.GetBranchConfig()
+	assert.Equal(t, map[string]string{"feat": "feat/", "sec": "security/"}, branchConfig.Prefixes)
+	assert.Equal(t, 40, branchConfig.MaxLength)
+	assert.Equal(t, DefaultTicketPattern, branchConfig.TicketPattern)
+
+	// Without branch.prefixes the prefixes follow the commit types
+	configFile, cleanup = testutils.TestConfig(t, `
+commit_types:
+  - feat
+  - sec
+`)
+	defer cleanup()
+	cfg, err = New(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"feat": "feature/", "sec": "sec/"}, cfg.GetBranchConfig().Prefixes)
+}
+
+func TestGetCommitTypes(t *testing.T) {
+	configFile, cleanup := testutils.TestConfig(t, `
+commit_types:
+  - "..."
+  - name: sec
+    description: security fixes
+    emoji: ":lock:"
+  - deps
+  - name: feat
+    description: a new feature
+`)
+	defer cleanup()
+
+	cfg, err := New(configFile)
+	require.NoError(t, err)
+
+	types := cfg.GetCommitTypes()
+	require.Len(t, types, len(committype.Defaults)+2)
+	feat, ok := committype.Find(types, "feat")
+	require.True(t, ok)
+	assert.Equal(t, committype.Type{Name: "feat", Description: "a new feature"}, feat)
+	assert.Equal(t, committype.Type{Name: "sec", Description: "security fixes", Emoji: ":lock:"}, types[len(types)-2])
+	assert.Equal(t, committype.Type{Name: "deps"}, types[len(types)-1])
+
+	// Appending to the inherited list keeps the built-in types
+	require.NoError(t, cfg.Remove(CommitTypesKey, ""))
+	require.NoError(t, cfg.Append(CommitTypesKey, "release"))
+	types = cfg.GetCommitTypes()
+	assert.Equal(t, append(committype.Names(committype.Defaults), "release"), committype.Names(types))
+
+	require.NoError(t, cfg.Remove(CommitTypesKey, "release"))
+	assert.Equal(t, committype.Defaults, cfg.GetCommitTypes())
+
+	// Built-in types can be removed, the inherited list is copied to the global config
+	require.NoError(t, cfg.Remove(CommitTypesKey, ""))
+	require.NoError(t, cfg.Remove(CommitTypesKey, "style"))
+	_, ok = committype.Find(cfg.GetCommitTypes(), "style")
+	assert.False(t, ok)
+	assert.Len(t, cfg.GetCommitTypes(), len(committype.Defaults)-1)
+	reloaded, err := New(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.GetCommitTypes(), reloaded.GetCommitTypes())
+	assert.Error(t, cfg.Remove(CommitTypesKey, "style"))
+	assert.Error(t, cfg.Remove("output.lang", "en"))
+
+	assert.Error(t, cfg.Set(CommitTypesKey, "sec"))
+	assert.Error(t, cfg.Set(CommitTypesKey, []interface{}{map[string]interface{}{"description": "no name"}}))
+	require.NoError(t, cfg.Set(CommitTypesKey, []interface{}{"sec", "deps"}))
+	assert.Equal(t, []string{"sec", "deps"}, committype.Names(cfg.GetCommitTypes()))
+}
+
+func TestGetOutputStyle(t *testing.T) {
+	configFile, cleanup := testutils.TestConfig(t, "output:\n  style: gitmoji\n")
+	defer cleanup()
+
+	cfg, err := New(configFile)
+	require.NoError(t, err)
+
+	style := cfg.GetOutputStyle()
+	assert.Equal(t, OutputStyleConfig{Style: OutputGitmoji, Emoji: EmojiCode}, style)
+	assert.Equal(t, ":sparkles: feat: add login", style.Format("✨ feat: add login"))
+
+	require.NoError(t, cfg.Set("output.emoji", EmojiUnicode))
+	assert.Equal(t, "✨ feat: add login", cfg.GetOutputStyle().Format(":sparkles: feat: add login"))
+
+	assert.Error(t, cfg.Set("output.style", "emoji"))
+	assert.Error(t, cfg.Set("output.emoji", "shortcode"))
+	require.NoError(t, cfg.Set("output.style", OutputPlain))
+	assert.Equal(t, ":sparkles: add login", cfg.GetOutputStyle().Format(":sparkles: add login"))
+}
+
+func TestGetUsagePrices(t *testing.T) {
+	configFile, cleanup := testutils.TestConfig(t, `
+usage:
+  prices:
+    gpt-4o:
+      prompt: 2
+    llama3:
+      prompt: 0.5
+      completion: 1
+`)
+	defer cleanup()
+
+	cfg, err := New(configFile)
+	require.NoError(t, err)
+
+	prices := cfg.GetUsagePrices()
+	assert.Equal(t, usage.Price{Prompt: 2, Completion: usage.DefaultPrices["gpt-4o"].Completion}, prices["gpt-4o"])
+	assert.Equal(t, usage.Price{Prompt: 0.5, Completion: 1}, prices["llama3"])
+	assert.Equal(t, usage.DefaultPrices["gpt-4o-mini"], prices["gpt-4o-mini"])
+}
+
+func TestGetProviderClientConfigLimits(t *testing.T) {
+	configFile, cleanup := testutils.TestConfig(t, `
+provider: openai
+budget:
+  on_exceed: refuse
+openai:
+  api_key: sk-test
+  model: gpt-4o
+  max_prompt_tokens: 8000
+  daily_token_budget: 100000
+  monthly_cost_budget: 12.5
+  fallback: ollama
+ollama:
+  api_key: local
+  model: llama3
+  fallback: openai
+`)
+	defer cleanup()
+
+	cfg, err := New(configFile)
+	require.NoError(t, err)
+
+	clientConfig, err := cfg.GetProviderClientConfig("openai")
+	require.NoError(t, err)
+	assert.Equal(t, types.Limits{
+		MaxPromptTokens:   8000,
+		DailyTokenBudget:  100000,
+		MonthlyCostBudget: 12.5,
+		PromptPrice:       usage.DefaultPrices["gpt-4o"].Prompt,
+		CompletionPrice:   usage.DefaultPrices["gpt-4o"].Completion,
+		Ledger:            filepath.Join(filepath.Dir(configFile), usage.LedgerFile),
+	}, clientConfig.Limits)
+
+	// The fallback cycle back to openai is cut
+	require.NotNil(t, clientConfig.Fallback)
+	assert.Equal(t, "ollama", clientConfig.Fallback.Provider)
+	assert.Nil(t, clientConfig.Fallback.Fallback)
+
+	require.NoError(t, cfg.Set("budget.on_exceed", "confirm"))
+	clientConfig, err = cfg.GetProviderClientConfig("openai")
+	require.NoError(t, err)
+	assert.True(t, clientConfig.Limits.Confirm)
+
+	// Values other than confirm and refuse are refused instead of silently confirming
+	assert.EqualError(t, cfg.Set("budget.on_exceed", "deny"), "invalid budget.on_exceed: deny, expected confirm or refuse")
+	assert.Error(t, cfg.Set("budget.on_exceed", true))
+	configFile, cleanup = testutils.TestConfig(t, `
+provider: openai
+budget:
+  on_exceed: 1
+openai:
+  api_key: sk-test
+`)
+	defer cleanup()
+	cfg, err = New(configFile)
+	require.NoError(t, err)
+	_, err = cfg.GetProviderClientConfig("openai")
+	assert.EqualError(t, err, "invalid budget.on_exceed: 1, expected confirm or refuse")
+}
+
+func TestGetProfile(t *testing.T) {
+	configFile, cleanup := testutils.TestConfig(t, `
+prompt:
+  brief_commit_message: "brief {{ diff }}"
+  profiles:
+    kernel:
+      brief_commit_message: "subsystem: {{ diff }}"
+      model: gpt-4o-mini
+      temperature: 0
+      max_tokens: 200
+    terse: {}
+`)
+	defer cleanup()
+
+	cfg, err := New(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kernel", "terse"}, cfg.GetProfileNames())
+
+	profile, err := cfg.GetProfile("kernel")
+	require.NoError(t, err)
+	assert.Equal(t, "subsystem: {{ diff }}", cfg.GetPrompt(profile, false))
+	assert.Equal(t, defaults.PromptDefaults["rich_commit_message"], cfg.GetPrompt(profile, true))
+	assert.Equal(t, defaults.PromptDefaults["translation"], cfg.GetTranslationPrompt(profile))
+	assert.Equal(t, "brief {{ diff }}", cfg.GetPrompt(nil, false))
+
+	clientConfig := &types.ClientConfig{Model: "gpt-4o", Temperature: 0.7, MaxTokens: 1024, TopP: 1}
+	profile.Apply(clientConfig)
+	assert.Equal(t, &types.ClientConfig{Model: "gpt-4o-mini", Temperature: 0, MaxTokens: 200, TopP: 1}, clientConfig)
+
+	_, err = cfg.GetProfile("missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kernel, terse")
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// RepoConfigFile is the name of the repository config file, read from the repository root
+	RepoConfigFile = ".gptcomet.yaml"
+	// InheritMarker is a list item replaced by the items of the same list in the lower layers,
+	// so ["...", "dist/"] appends to the inherited list while a list without it replaces it
+	InheritMarker = "..."
+	// envPrefix is the prefix of the environment variables overriding config keys,
+	// GPTCOMET_OUTPUT__LANG sets output.lang
+	envPrefix = "GPTCOMET_"
+)
+
+// secretKeys are the keys that must not be set in the repository config: the credentials,
+// since the file is meant to be committed, and the keys deciding where requests holding
+// the credentials of the global config are sent, since the file comes with cloned repositories
+var secretKeys = map[string]bool{
+	"api_key":         true,
+	"extra_headers":   true,
+	"api_base":        true,
+	"proxy":           true,
+	"completion_path": true,
+	"answer_path":     true,
+	"fallback":        true,
+}
+
+// secretRootKeys are the top level keys that must not be set in the repository config
+var secretRootKeys = map[string]bool{
+	"provider": true,
+}
+
+// overrides are the values set on the command line, the highest layer
+var overrides = map[string]interface{}{}
+
+// SetOverrides sets config values by key, e.g. "output.lang", that take precedence over
+// every config file and environment variable. They are never saved.
+func SetOverrides(values map[string]interface{}) {
+	overrides = make(map[string]interface{}, len(values))
+	for key, value := range values {
+		setNestedValue(overrides, strings.Split(key, "."), value)
+	}
+}
+
+// ParseOverride parses a "key=value" command line override. The value is decoded as
+// YAML, so numbers, booleans and lists keep their type.
+func ParseOverride(arg string) (string, interface{}, error) {
+	key, raw, ok := strings.Cut(arg, "=")
+	if !ok || key == "" {
+		return "", nil, fmt.Errorf("invalid override %q, expected key=value", arg)
+	}
+	return key, parseScalar(raw), nil
+}
+
+// parseScalar decodes raw as YAML, keeping it as a string if it is not valid YAML
+func parseScalar(raw string) interface{} {
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+		return raw
+	}
+	return value
+}
+
+// defaultLayer returns the built-in settings shared by every config. Provider sections
+// are left out, so only the providers of the config files are configured.
+func defaultLayer() (map[string]interface{}, error) {
+	config := defaultConfig()
+	layer := map[string]interface{}{
+		"file_ignore": config["file_ignore"],
+		"output":      config["output"],
+		"console":     config["console"],
+		"prompt":      config["prompt"],
+		// Commit types are only in the defaults so lists in config files can extend them
+		CommitTypesKey: defaultCommitTypes(),
+	}
+	// Round trip through YAML so the layer holds the same types as a loaded file
+	data, err := yaml.Marshal(layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal default config: %w", err)
+	}
+	layer = make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &layer); err != nil {
+		return nil, fmt.Errorf("failed to parse default config: %w", err)
+	}
+	return layer, nil
+}
+
+// findRepoConfig returns the path of the repository config of the repository holding dir,
+// empty if dir is not in a repository or the repository has no config
+func findRepoConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			path := filepath.Join(dir, RepoConfigFile)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadRepoConfig reads the repository config at path, refusing secrets and the settings
+// deciding where requests are sent
+func loadRepoConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository config: %w", err)
+	}
+	layer := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &layer); err != nil {
+		return nil, fmt.Errorf("failed to parse repository config %s: %w", path, err)
+	}
+	if key := findSecret(layer, ""); key != "" {
+		return nil, fmt.Errorf("%s must not be set in the repository config %s, set it in the global config instead", key, path)
+	}
+	return layer, nil
+}
+
+// findSecret returns the first key of layer, in sorted order, that is a secret or
+// decides where requests are sent
+func findSecret(layer map[string]interface{}, prefix string) string {
+	keys := make([]string, 0, len(layer))
+	for key := range layer {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if secretKeys[key] || (prefix == "" && secretRootKeys[key]) {
+			return prefix + key
+		}
+		if section, ok := layer[key].(map[string]interface{}); ok {
+			if found := findSecret(section, prefix+key+"."); found != "" {
+				return found
+			}
+		}
+	}
+	return ""
+}
+
+// envLayer returns the values of the GPTCOMET_<SECTION>__<KEY> environment variables
+// for the top level keys of base, so unrelated variables such as GPTCOMET_SERVE_TOKEN
+// are not mistaken for config
+func envLayer(base map[string]interface{}, environ []string) map[string]interface{} {
+	layer := make(map[string]interface{})
+	for _, env := range environ {
+		name, raw, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		keys := strings.Split(strings.ToLower(strings.TrimPrefix(name, envPrefix)), "__")
+		if _, ok := base[keys[0]]; !ok {
+			continue
+		}
+		setNestedValue(layer, keys, parseScalar(raw))
+	}
+	return layer
+}
+
+// mergeLayers deeply merges layers into a new map, later layers taking precedence.
+// Maps are merged key by key, lists are replaced unless they hold InheritMarker and
+// other values are replaced.
+func mergeLayers(layers ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		merged = mergeMaps(merged, layer)
+	}
+	return merged
+}
+
+// mergeMaps returns a copy of lower with upper merged over it
+func mergeMaps(lower, upper map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(lower)+len(upper))
+	for key, value := range lower {
+		merged[key] = copyValue(value)
+	}
+	for key, value := range upper {
+		merged[key] = mergeValues(merged[key], value)
+	}
+	return merged
+}
+
+// mergeValues merges upper over lower
+func mergeValues(lower, upper interface{}) interface{} {
+	switch u := upper.(type) {
+	case map[string]interface{}:
+		if l, ok := lower.(map[string]interface{}); ok {
+			return mergeMaps(l, u)
+		}
+		return mergeMaps(nil, u)
+	case []interface{}:
+		l, _ := lower.([]interface{})
+		merged := make([]interface{}, 0, len(u)+len(l))
+		for _, item := range u {
+			if item == InheritMarker {
+				merged = append(merged, l...)
+				continue
+			}
+			merged = append(merged, copyValue(item))
+		}
+		return merged
+	default:
+		return upper
+	}
+}
+
+// copyValue returns a deep copy of the maps and lists of value
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return mergeMaps(nil, v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = copyValue(item)
+		}
+		return list
+	default:
+		return value
+	}
+}
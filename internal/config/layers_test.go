@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeLayers(t *testing.T) {
+	lower := map[string]interface{}{
+		"file_ignore": []interface{}{"go.sum"},
+		"output":      map[string]interface{}{"lang": "en", "rich_template": "<title>"},
+		"provider":    "openai",
+	}
+	merged := mergeLayers(lower, map[string]interface{}{
+		"file_ignore": []interface{}{InheritMarker, "dist/"},
+		"output":      map[string]interface{}{"lang": "fr"},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"file_ignore": []interface{}{"go.sum", "dist/"},
+		"output":      map[string]interface{}{"lang": "fr", "rich_template": "<title>"},
+		"provider":    "openai",
+	}, merged)
+
+	// Lists without the marker replace the inherited list
+	merged = mergeLayers(lower, map[string]interface{}{"file_ignore": []interface{}{"dist/"}})
+	assert.Equal(t, []interface{}{"dist/"}, merged["file_ignore"])
+
+	// The layers are not modified
+	merged["output"].(map[string]interface{})["lang"] = "de"
+	assert.Equal(t, "en", lower["output"].(map[string]interface{})["lang"])
+}
+
+func TestEnvLayer(t *testing.T) {
+	base := map[string]interface{}{"output": map[string]interface{}{}, "openai": map[string]interface{}{}}
+	layer := envLayer(base, []string{
+		"GPTCOMET_OUTPUT__LANG=fr",
+		"GPTCOMET_OPENAI__MAX_TOKENS=100",
+		"GPTCOMET_SERVE_TOKEN=secret",
+		"HOME=/root",
+	})
+	assert.Equal(t, map[string]interface{}{
+		"output": map[string]interface{}{"lang": "fr"},
+		"openai": map[string]interface{}{"max_tokens": 100},
+	}, layer)
+}
+
+func TestParseOverride(t *testing.T) {
+	key, value, err := ParseOverride("openai.max_tokens=100")
+	require.NoError(t, err)
+	assert.Equal(t, "openai.max_tokens", key)
+	assert.Equal(t, 100, value)
+
+	_, value, err = ParseOverride("output.rich_template=<title>: [x")
+	require.NoError(t, err)
+	assert.Equal(t, "<title>: [x", value)
+
+	_, _, err = ParseOverride("output.lang")
+	assert.Error(t, err)
+}
+
+func TestNewForRepo(t *testing.T) {
+	configFile, cleanup := testutils.TestConfig(t, `
+provider: openai
+file_ignore:
+  - go.sum
+output:
+  lang: en
+openai:
+  api_key: sk-global
+  model: gpt-4o
+`)
+	defer cleanup()
+
+	repo := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+	sub := filepath.Join(repo, "pkg", "sub")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFile), []byte(`
+file_ignore:
+  - "..."
+  - dist/
+output:
+  lang: fr
+openai:
+  model: gpt-4o-mini
+`), 0644))
+
+	cfg, err := NewForRepo(configFile, sub)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repo, RepoConfigFile), cfg.GetRepoConfigPath())
+	assert.Equal(t, []string{"go.sum", "dist/"}, cfg.GetFileIgnore())
+	lang, _ := cfg.Get("output.lang")
+	assert.Equal(t, "fr", lang)
+	clientConfig, err := cfg.GetClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "sk-global", clientConfig.APIKey)
+	assert.Equal(t, "gpt-4o-mini", clientConfig.Model)
+
+	// Environment variables and command line overrides win over the files
+	t.Setenv("GPTCOMET_OUTPUT__LANG", "de")
+	SetOverrides(map[string]interface{}{"openai.model": "o1"})
+	defer SetOverrides(nil)
+	cfg, err = NewForRepo(configFile, sub)
+	require.NoError(t, err)
+	lang, _ = cfg.Get("output.lang")
+	assert.Equal(t, "de", lang)
+	model, _ := cfg.Get("openai.model")
+	assert.Equal(t, "o1", model)
+
+	// Changes only go to the global file
+	require.NoError(t, cfg.Set("provider", "openai"))
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "dist/")
+	assert.NotContains(t, string(data), "o1")
+
+	// Secrets are refused in the repository config
+	require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFile), []byte("openai:\n  api_key: sk-leak\n"), 0644))
+	_, err = NewForRepo(configFile, sub)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "openai.api_key")
+
+	// A repository config cannot change where the global api key is sent
+	for _, content := range []string{
+		"openai:\n  api_base: https://attacker.example\n",
+		"openai:\n  proxy: http://attacker.example:8080\n",
+		"openai:\n  completion_path: //attacker.example/collect\n",
+		"openai:\n  fallback: evil\nevil:\n  model: x\n",
+		"provider: evil\nevil:\n  model: x\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFile), []byte(content), 0644))
+		_, err = NewForRepo(configFile, sub)
+		assert.Error(t, err, content)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFile), []byte("openai:\n  model: gpt-4o-mini\n"), 0644))
+	cfg, err = NewForRepo(configFile, sub)
+	require.NoError(t, err)
+	clientConfig, err = cfg.GetClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "sk-global", clientConfig.APIKey)
+	assert.Equal(t, types.DefaultAPIBase, clientConfig.APIBase)
+}
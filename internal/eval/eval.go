@@ -0,0 +1,271 @@
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/committype"
+)
+
+// ConventionalTypes are the commit types accepted as conventional
+var ConventionalTypes = committype.Names(committype.Defaults)
+
+// MaxSubjectLength is the subject length above which the length score decreases
+const MaxSubjectLength = 72
+
+var conventionalRe = regexp.MustCompile(`^([a-zA-Z]+)(\([^)]*\))?!?:\s*\S`)
+
+// Sample is a dataset entry: a diff and the reference commit message written for it
+type Sample struct {
+	ID        string `json:"id"`
+	Diff      string `json:"diff"`
+	Reference string `json:"reference"`
+}
+
+// LoadDataset reads a JSONL dataset. Samples without an id are named after their line number.
+func LoadDataset(path string) ([]Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var sample Sample
+		if err := json.Unmarshal([]byte(text), &sample); err != nil {
+			return nil, fmt.Errorf("invalid dataset entry on line %d: %w", line, err)
+		}
+		if sample.Diff == "" || sample.Reference == "" {
+			return nil, fmt.Errorf("dataset entry on line %d needs both diff and reference", line)
+		}
+		if sample.ID == "" {
+			sample.ID = strconv.Itoa(line)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("dataset %s is empty", path)
+	}
+	return samples, nil
+}
+
+// Subject returns the first non-empty line of a commit message
+func Subject(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// CommitType returns the conventional commit type of subject, if it has a known one
+func CommitType(subject string) (string, bool) {
+	match := conventionalRe.FindStringSubmatch(subject)
+	if match == nil {
+		return "", false
+	}
+	typ := strings.ToLower(match[1])
+	for _, t := range ConventionalTypes {
+		if t == typ {
+			return typ, true
+		}
+	}
+	return "", false
+}
+
+// LengthScore scores the subject length: 1 between 10 and MaxSubjectLength characters,
+// decreasing linearly for shorter and longer subjects
+func LengthScore(subject string) float64 {
+	n := len([]rune(subject))
+	switch {
+	case n < 10:
+		return float64(n) / 10
+	case n <= MaxSubjectLength:
+		return 1
+	default:
+		score := 1 - float64(n-MaxSubjectLength)/MaxSubjectLength
+		if score < 0 {
+			return 0
+		}
+		return score
+	}
+}
+
+var wordRe = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// TokenOverlap returns the F1 score of the words shared by candidate and reference
+func TokenOverlap(candidate, reference string) float64 {
+	count := func(text string) map[string]int {
+		counts := make(map[string]int)
+		for _, word := range wordRe.FindAllString(strings.ToLower(text), -1) {
+			counts[word]++
+		}
+		return counts
+	}
+	cand := count(candidate)
+	ref := count(reference)
+
+	candTotal, refTotal, shared := 0, 0, 0
+	for _, n := range ref {
+		refTotal += n
+	}
+	for word, n := range cand {
+		candTotal += n
+		if m := ref[word]; m > 0 {
+			shared += min(n, m)
+		}
+	}
+	if shared == 0 {
+		return 0
+	}
+	precision := float64(shared) / float64(candTotal)
+	recall := float64(shared) / float64(refTotal)
+	return 2 * precision * recall / (precision + recall)
+}
+
+// Result is the outcome of a target on a sample
+type Result struct {
+	SampleID string        `json:"sample_id"`
+	Target   string        `json:"target"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+
+	SubjectLength int     `json:"subject_length"`
+	LengthScore   float64 `json:"length_score"`
+	Conventional  bool    `json:"conventional"`
+	// TypeMatch is nil when the reference has no conventional type to compare with
+	TypeMatch *bool   `json:"type_match,omitempty"`
+	Overlap   float64 `json:"overlap"`
+	// Judge is the LLM judge score between 0 and 1, nil if not judged
+	Judge *float64 `json:"judge,omitempty"`
+}
+
+// Score computes the heuristic metrics of output against the reference of sample
+func Score(sample Sample, target, output string, latency time.Duration) Result {
+	subject := Subject(output)
+	result := Result{
+		SampleID:      sample.ID,
+		Target:        target,
+		Output:        output,
+		Latency:       latency,
+		SubjectLength: len([]rune(subject)),
+		LengthScore:   LengthScore(subject),
+		Overlap:       TokenOverlap(output, sample.Reference),
+	}
+
+	typ, ok := CommitType(subject)
+	result.Conventional = ok
+	if refType, refOK := CommitType(Subject(sample.Reference)); refOK {
+		match := ok && typ == refType
+		result.TypeMatch = &match
+	}
+	return result
+}
+
+var judgeScoreRe = regexp.MustCompile(`\b(10|[1-9])\b`)
+
+// ParseJudgeScore reads the first 1 to 10 rating in a judge answer and normalizes it to 0..1
+func ParseJudgeScore(answer string) (float64, error) {
+	match := judgeScoreRe.FindString(answer)
+	if match == "" {
+		return 0, fmt.Errorf("no score found in judge answer: %q", answer)
+	}
+	n, _ := strconv.Atoi(match)
+	return float64(n-1) / 9, nil
+}
+
+// Summary aggregates the results of a target
+type Summary struct {
+	Target        string
+	Samples       int
+	Failures      int
+	AvgLength     float64
+	LengthScore   float64
+	Conventional  float64
+	TypeAgreement float64
+	TypeSamples   int
+	Overlap       float64
+	Judge         float64
+	JudgeSamples  int
+	AvgLatency    time.Duration
+}
+
+// Summarize aggregates results per target, keeping the order targets first appear in
+func Summarize(results []Result) []Summary {
+	var (
+		order   []string
+		byName  = make(map[string]*Summary)
+		latency = make(map[string]time.Duration)
+	)
+	for _, r := range results {
+		s, ok := byName[r.Target]
+		if !ok {
+			s = &Summary{Target: r.Target}
+			byName[r.Target] = s
+			order = append(order, r.Target)
+		}
+		s.Samples++
+		if r.Error != "" {
+			s.Failures++
+			continue
+		}
+		latency[r.Target] += r.Latency
+		s.AvgLength += float64(r.SubjectLength)
+		s.LengthScore += r.LengthScore
+		s.Overlap += r.Overlap
+		if r.Conventional {
+			s.Conventional++
+		}
+		if r.TypeMatch != nil {
+			s.TypeSamples++
+			if *r.TypeMatch {
+				s.TypeAgreement++
+			}
+		}
+		if r.Judge != nil {
+			s.JudgeSamples++
+			s.Judge += *r.Judge
+		}
+	}
+
+	summaries := make([]Summary, 0, len(order))
+	for _, name := range order {
+		s := byName[name]
+		if ok := s.Samples - s.Failures; ok > 0 {
+			n := float64(ok)
+			s.AvgLength /= n
+			s.LengthScore /= n
+			s.Overlap /= n
+			s.Conventional /= n
+			s.AvgLatency = latency[name] / time.Duration(ok)
+		}
+		if s.TypeSamples > 0 {
+			s.TypeAgreement /= float64(s.TypeSamples)
+		}
+		if s.JudgeSamples > 0 {
+			s.Judge /= float64(s.JudgeSamples)
+		}
+		summaries = append(summaries, *s)
+	}
+	return summaries
+}
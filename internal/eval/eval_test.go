@@ -0,0 +1,115 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDataset(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dataset.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"a","diff":"diff --git a/x b/x","reference":"fix: x"}
+
+{"diff":"diff --git a/y b/y","reference":"feat: y"}
+`), 0644))
+
+	samples, err := LoadDataset(path)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, "a", samples[0].ID)
+	assert.Equal(t, "3", samples[1].ID)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"diff":"d"}`), 0644))
+	_, err = LoadDataset(path)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(""), 0644))
+	_, err = LoadDataset(path)
+	assert.Error(t, err)
+}
+
+func TestCommitType(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+		ok      bool
+	}{
+		{"feat: add login", "feat", true},
+		{"fix(api)!: handle nil", "fix", true},
+		{"Docs: update readme", "docs", true},
+		{"update readme", "", false},
+		{"wip: stuff", "", false},
+	}
+	for _, tt := range tests {
+		typ, ok := CommitType(tt.subject)
+		assert.Equal(t, tt.want, typ, tt.subject)
+		assert.Equal(t, tt.ok, ok, tt.subject)
+	}
+}
+
+func TestLengthScore(t *testing.T) {
+	assert.Equal(t, 0.5, LengthScore("fix: a"[:5]))
+	assert.Equal(t, 1.0, LengthScore("fix: handle nil config"))
+	assert.InDelta(t, 0.5, LengthScore(string(make([]byte, MaxSubjectLength+36))), 1e-9)
+	assert.Equal(t, 0.0, LengthScore(string(make([]byte, 3*MaxSubjectLength))))
+}
+
+func TestTokenOverlap(t *testing.T) {
+	assert.Equal(t, 1.0, TokenOverlap("fix: handle nil config", "Fix: handle nil config"))
+	assert.Equal(t, 0.0, TokenOverlap("feat: add login", "docs: readme"))
+	// shared: fix, nil -> precision 2/3, recall 2/4
+	assert.InDelta(t, 4.0/7.0, TokenOverlap("fix nil panic", "fix: nil pointer crash"), 1e-9)
+}
+
+func TestScoreAndSummarize(t *testing.T) {
+	sample := Sample{ID: "1", Reference: "fix: handle nil config"}
+	good := Score(sample, "openai/gpt-4o", "fix: handle nil config in loader\n\n- details", 100*time.Millisecond)
+	assert.True(t, good.Conventional)
+	require.NotNil(t, good.TypeMatch)
+	assert.True(t, *good.TypeMatch)
+	assert.Equal(t, len("fix: handle nil config in loader"), good.SubjectLength)
+
+	bad := Score(sample, "openai/gpt-4o", "Handle config", 300*time.Millisecond)
+	assert.False(t, bad.Conventional)
+	assert.False(t, *bad.TypeMatch)
+	judge := 0.5
+	bad.Judge = &judge
+
+	noType := Score(Sample{ID: "2", Reference: "Update things"}, "ollama/llama3", "feat: x", time.Second)
+	assert.Nil(t, noType.TypeMatch)
+
+	failed := Result{SampleID: "2", Target: "ollama/llama3", Error: "timeout"}
+
+	summaries := Summarize([]Result{good, bad, noType, failed})
+	require.Len(t, summaries, 2)
+	s := summaries[0]
+	assert.Equal(t, "openai/gpt-4o", s.Target)
+	assert.Equal(t, 2, s.Samples)
+	assert.Equal(t, 0.5, s.Conventional)
+	assert.Equal(t, 0.5, s.TypeAgreement)
+	assert.Equal(t, 1, s.JudgeSamples)
+	assert.Equal(t, 0.5, s.Judge)
+	assert.Equal(t, 200*time.Millisecond, s.AvgLatency)
+
+	s = summaries[1]
+	assert.Equal(t, 1, s.Failures)
+	assert.Equal(t, 0, s.TypeSamples)
+}
+
+func TestParseJudgeScore(t *testing.T) {
+	score, err := ParseJudgeScore("Score: 10")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+
+	score, err = ParseJudgeScore("1")
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, score)
+
+	_, err = ParseJudgeScore("great")
+	assert.Error(t, err)
+}
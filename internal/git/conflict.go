@@ -0,0 +1,220 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	markerOurs   = "<<<<<<<"
+	markerBase   = "|||||||"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// Conflict is a single conflicted hunk delimited by merge conflict markers
+type Conflict struct {
+	OursLabel   string
+	BaseLabel   string
+	TheirsLabel string
+	Ours        string
+	Base        string
+	Theirs      string
+	// HasBase reports whether the hunk carries a diff3 base section
+	HasBase bool
+	// Resolution replaces the hunk when the file is rendered, nil keeps the markers
+	Resolution *string
+
+	raw string
+}
+
+// Resolve sets the text that replaces the conflict
+func (c *Conflict) Resolve(text string) {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	c.Resolution = &text
+}
+
+// ConflictFile is the content of a file containing merge conflicts
+type ConflictFile struct {
+	Path      string
+	Conflicts []*Conflict
+	// segments holds the file content in order, either plain text or a conflict
+	segments []interface{}
+}
+
+// Resolved reports whether every conflict of the file has a resolution
+func (f *ConflictFile) Resolved() bool {
+	for _, c := range f.Conflicts {
+		if c.Resolution == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Render returns the file content with resolved conflicts replaced by their resolution
+// and unresolved conflicts left as they were.
+func (f *ConflictFile) Render() string {
+	var sb strings.Builder
+	for _, seg := range f.segments {
+		switch s := seg.(type) {
+		case string:
+			sb.WriteString(s)
+		case *Conflict:
+			if s.Resolution != nil {
+				sb.WriteString(*s.Resolution)
+			} else {
+				sb.WriteString(s.raw)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// markerLabel returns the label following a conflict marker, if line starts with it
+func markerLabel(line, marker string) (string, bool) {
+	trimmed := strings.TrimRight(line, "\r\n")
+	if trimmed != marker && !strings.HasPrefix(trimmed, marker+" ") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(trimmed, marker)), true
+}
+
+// ParseConflictFile splits content into plain text and conflict hunks.
+// Both the default merge style and the diff3 style with a base section are supported.
+func ParseConflictFile(path, content string) (*ConflictFile, error) {
+	file := &ConflictFile{Path: path}
+	lines := strings.SplitAfter(content, "\n")
+
+	var text strings.Builder
+	for i := 0; i < len(lines); i++ {
+		label, ok := markerLabel(lines[i], markerOurs)
+		if !ok {
+			text.WriteString(lines[i])
+			continue
+		}
+
+		if text.Len() > 0 {
+			file.segments = append(file.segments, text.String())
+			text.Reset()
+		}
+
+		conflict := &Conflict{OursLabel: label}
+		var raw, ours, base, theirs strings.Builder
+		raw.WriteString(lines[i])
+		section := &ours
+		closed := false
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			raw.WriteString(line)
+			if label, ok := markerLabel(line, markerBase); ok && section == &ours {
+				conflict.HasBase = true
+				conflict.BaseLabel = label
+				section = &base
+				continue
+			}
+			if strings.TrimRight(line, "\r\n") == markerSep && section != &theirs {
+				section = &theirs
+				continue
+			}
+			if label, ok := markerLabel(line, markerTheirs); ok && section == &theirs {
+				conflict.TheirsLabel = label
+				closed = true
+				break
+			}
+			section.WriteString(line)
+		}
+		if !closed {
+			return nil, fmt.Errorf("unterminated conflict marker in %s", path)
+		}
+
+		conflict.Ours = ours.String()
+		conflict.Base = base.String()
+		conflict.Theirs = theirs.String()
+		conflict.raw = raw.String()
+		file.Conflicts = append(file.Conflicts, conflict)
+		file.segments = append(file.segments, conflict)
+	}
+	if text.Len() > 0 {
+		file.segments = append(file.segments, text.String())
+	}
+
+	return file, nil
+}
+
+// GetConflictedFiles returns the files with unresolved merge conflicts.
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//
+// Returns:
+//   - []string: The conflicted file paths, or nil if there are none
+//   - error: An error if the git command fails
+func (g *GitVCS) GetConflictedFiles(repoPath string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	output, err := g.runCommand(cmd, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// StageFiles adds the given files to the index, marking their conflicts as resolved
+func (g *GitVCS) StageFiles(repoPath string, files []string) error {
+	args := append([]string{"add", "--"}, files...)
+	_, err := g.runCommand(exec.Command("git", args...), repoPath)
+	return err
+}
+
+// GetConflictSideCommits returns the commits of each side of an ongoing merge, rebase,
+// cherry-pick or revert that touched path since the sides diverged, newest first.
+// Both slices are nil when no such operation is in progress.
+func (g *GitVCS) GetConflictSideCommits(repoPath, path string, maxCount int) ([]Commit, []Commit, error) {
+	var theirs string
+	for _, ref := range []string{"MERGE_HEAD", "REBASE_HEAD", "CHERRY_PICK_HEAD", "REVERT_HEAD"} {
+		if _, err := g.runCommand(exec.Command("git", "rev-parse", "-q", "--verify", ref), repoPath); err == nil {
+			theirs = ref
+			break
+		}
+	}
+	if theirs == "" {
+		return nil, nil, nil
+	}
+
+	base, err := g.runCommand(exec.Command("git", "merge-base", "HEAD", theirs), repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	base = strings.TrimSpace(base)
+
+	sideCommits := func(ref string) ([]Commit, error) {
+		args := []string{"log", "--no-color", "--date=short", logFormat}
+		if maxCount > 0 {
+			args = append(args, fmt.Sprintf("--max-count=%d", maxCount))
+		}
+		args = append(args, base+".."+ref, "--", path)
+		output, err := g.runCommand(exec.Command("git", args...), repoPath)
+		if err != nil {
+			return nil, err
+		}
+		return parseLog(output), nil
+	}
+
+	ours, err := sideCommits("HEAD")
+	if err != nil {
+		return nil, nil, err
+	}
+	theirsCommits, err := sideCommits(theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ours, theirsCommits, nil
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConflictFile(t *testing.T) {
+	content := "package main\n" +
+		"<<<<<<< HEAD\n" +
+		"ours line\n" +
+		"=======\n" +
+		"theirs line\n" +
+		">>>>>>> feature\n" +
+		"middle\n" +
+		"<<<<<<< HEAD\n" +
+		"a\n" +
+		"||||||| base\n" +
+		"b\n" +
+		"=======\n" +
+		"c\n" +
+		">>>>>>> feature\n" +
+		"end\n"
+
+	file, err := ParseConflictFile("main.go", content)
+	require.NoError(t, err)
+	require.Len(t, file.Conflicts, 2)
+
+	first := file.Conflicts[0]
+	assert.Equal(t, "HEAD", first.OursLabel)
+	assert.Equal(t, "feature", first.TheirsLabel)
+	assert.Equal(t, "ours line\n", first.Ours)
+	assert.Equal(t, "theirs line\n", first.Theirs)
+	assert.False(t, first.HasBase)
+
+	second := file.Conflicts[1]
+	assert.True(t, second.HasBase)
+	assert.Equal(t, "base", second.BaseLabel)
+	assert.Equal(t, "a\n", second.Ours)
+	assert.Equal(t, "b\n", second.Base)
+	assert.Equal(t, "c\n", second.Theirs)
+
+	// Unresolved conflicts render unchanged
+	assert.Equal(t, content, file.Render())
+	assert.False(t, file.Resolved())
+
+	first.Resolve("merged line")
+	assert.False(t, file.Resolved())
+	second.Resolve("")
+	assert.True(t, file.Resolved())
+	assert.Equal(t, "package main\nmerged line\nmiddle\nend\n", file.Render())
+}
+
+func TestParseConflictFile_Unterminated(t *testing.T) {
+	_, err := ParseConflictFile("main.go", "<<<<<<< HEAD\nours\n=======\ntheirs\n")
+	assert.Error(t, err)
+}
+
+func TestGetConflictedFiles(t *testing.T) {
+	_, dir, cleanup := setupVCSTest(t, Git)
+	defer cleanup()
+	vcs := &GitVCS{}
+
+	file := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("base\n"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "add", "main.go"))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "-m", "base"))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "checkout", "-b", "feature"))
+	require.NoError(t, os.WriteFile(file, []byte("theirs\n"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "-am", "feat: their change"))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "checkout", "-"))
+	require.NoError(t, os.WriteFile(file, []byte("ours\n"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "-am", "fix: our change"))
+
+	files, err := vcs.GetConflictedFiles(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	ours, theirs, err := vcs.GetConflictSideCommits(dir, "main.go", 5)
+	require.NoError(t, err)
+	assert.Nil(t, ours)
+	assert.Nil(t, theirs)
+
+	// The merge is expected to stop on a conflict
+	assert.Error(t, testutils.RunGitCommand(t, dir, "merge", "feature"))
+
+	files, err = vcs.GetConflictedFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, files)
+
+	ours, theirs, err = vcs.GetConflictSideCommits(dir, "main.go", 5)
+	require.NoError(t, err)
+	require.Len(t, ours, 1)
+	require.Len(t, theirs, 1)
+	assert.Equal(t, "fix: our change", ours[0].Subject)
+	assert.Equal(t, "feat: their change", theirs[0].Subject)
+
+	require.NoError(t, os.WriteFile(file, []byte("resolved\n"), 0644))
+	require.NoError(t, vcs.StageFiles(dir, []string{"main.go"}))
+	files, err = vcs.GetConflictedFiles(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -133,14 +133,11 @@ func ShouldIgnoreFile(file string, ignorePatterns []string) bool {
 // The function will return an empty string if there are no staged files in the repository.
 // If the git command fails, it returns a detailed error message including the exit code.
 func (g *GitVCS) GetStagedDiffFiltered(repoPath string, cfgManager *config.Manager) (string, error) {
-	// First get staged files
-	files, err := g.GetStagedFiles(repoPath)
-	debug.Printf("Staged files: %v", files)
-	if err != nil {
-		return "", err
-	}
+	return g.GetStagedDiffIgnoring(repoPath, IgnorePatterns(cfgManager))
+}
 
-	// Get ignore patterns from config
+// IgnorePatterns returns the patterns of the files excluded from diffs, set under the "file_ignore" key
+func IgnorePatterns(cfgManager *config.Manager) []string {
 	var ignorePatterns []string
 	if patterns, ok := cfgManager.Get("file_ignore"); ok {
 		if patternList, ok := patterns.([]interface{}); ok {
@@ -151,14 +148,31 @@ func (g *GitVCS) GetStagedDiffFiltered(repoPath string, cfgManager *config.Manag
 			}
 		}
 	}
+	return ignorePatterns
+}
 
-	// Filter files based on ignore patterns
-	var filteredFiles []string
+// filterIgnored returns the files that do not match ignorePatterns
+func filterIgnored(files []string, ignorePatterns []string) []string {
+	var filtered []string
 	for _, file := range files {
 		if !ShouldIgnoreFile(file, ignorePatterns) {
-			filteredFiles = append(filteredFiles, file)
+			filtered = append(filtered, file)
 		}
 	}
+	return filtered
+}
+
+// GetStagedDiffIgnoring returns the git diff for staged changes, excluding files that match ignorePatterns
+func (g *GitVCS) GetStagedDiffIgnoring(repoPath string, ignorePatterns []string) (string, error) {
+	// First get staged files
+	files, err := g.GetStagedFiles(repoPath)
+	debug.Printf("Staged files: %v", files)
+	if err != nil {
+		return "", err
+	}
+
+	// Filter files based on ignore patterns
+	filteredFiles := filterIgnored(files, ignorePatterns)
 	debug.Printf("Filtered files: %v", filteredFiles)
 
 	if len(filteredFiles) == 0 {
@@ -200,6 +214,45 @@ func (g *GitVCS) GetCurrentBranch(repoPath string) (string, error) {
 	return strings.TrimSpace(output), err
 }
 
+// GetRepoRoot returns the absolute path of the top-level directory of the git repository
+// containing repoPath.
+//
+// Parameters:
+//   - repoPath: The file system path inside the git repository
+//
+// Returns:
+//   - string: The repository root directory
+//   - error: An error if the git command fails or if repoPath is not inside a git repository
+func (g *GitVCS) GetRepoRoot(repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	output, err := g.runCommand(cmd, repoPath)
+	return strings.TrimSpace(output), err
+}
+
+// GetTrackedFiles returns the tracked files matching the pathspecs, relative to repoPath.
+//
+// Parameters:
+//   - repoPath: The file system path inside the git repository
+//   - pathspecs: Git pathspecs such as "*/go.mod", none means every tracked file
+//
+// Returns:
+//   - []string: The matching files
+//   - error: An error if the git command fails
+func (g *GitVCS) GetTrackedFiles(repoPath string, pathspecs ...string) ([]string, error) {
+	args := append([]string{"ls-files", "--"}, pathspecs...)
+	output, err := g.runCommand(exec.Command("git", args...), repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
 // GetCommitInfo returns formatted information about the commit
 // If commitHash is empty, returns info about the last commit
 //
@@ -282,6 +335,37 @@ func (g *GitVCS) CreateCommit(repoPath string, message string) error {
 	return err
 }
 
+// CheckRefFormat validates a branch name with "git check-ref-format --branch"
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - name: The branch name to validate
+//
+// Returns:
+//   - error: An error if the name is not a valid branch name
+func (g *GitVCS) CheckRefFormat(repoPath string, name string) error {
+	cmd := exec.Command("git", "check-ref-format", "--branch", name)
+	_, err := g.runCommand(cmd, repoPath)
+	if err != nil {
+		return fmt.Errorf("invalid branch name %q: %w", name, err)
+	}
+	return nil
+}
+
+// CreateBranch creates a new branch from HEAD and switches to it
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - name: The name of the new branch
+//
+// Returns:
+//   - error: An error if the git command fails, e.g. the branch already exists
+func (g *GitVCS) CreateBranch(repoPath string, name string) error {
+	cmd := exec.Command("git", "checkout", "-b", name)
+	_, err := g.runCommand(cmd, repoPath)
+	return err
+}
+
 // runCommand 执行命令并返回输出
 func (g *GitVCS) runCommand(cmd *exec.Cmd, repoPath string) (string, error) {
 	debug.Printf("Running command: %v", cmd.Args)
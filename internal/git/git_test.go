@@ -154,3 +154,28 @@ func TestNewVCS(t *testing.T) {
 		})
 	}
 }
+
+func TestBranchHelpers(t *testing.T) {
+	_, dir, cleanup := setupVCSTest(t, Git)
+	defer cleanup()
+	vcs := &GitVCS{}
+
+	assert.NoError(t, vcs.CheckRefFormat(dir, "feature/add-login"))
+	assert.Error(t, vcs.CheckRefFormat(dir, "bad..name"))
+	assert.Error(t, vcs.CheckRefFormat(dir, "bad name"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "add", "a.txt"))
+	require.NoError(t, vcs.CreateCommit(dir, "init"))
+
+	require.NoError(t, vcs.CreateBranch(dir, "feature/add-login"))
+	branch, err := vcs.GetCurrentBranch(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/add-login", branch)
+
+	root, err := vcs.GetRepoRoot(dir)
+	require.NoError(t, err)
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	assert.Equal(t, resolved, root)
+}
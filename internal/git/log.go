@@ -0,0 +1,212 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit represents a single commit parsed from git log output
+type Commit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Date    string
+	Subject string
+	Body    string
+	// Extra holds whatever git printed after the commit header,
+	// e.g. the patch for `git log -L` or the file list for `--name-only`.
+	Extra string
+}
+
+// logFormat separates commits with ASCII record separators and fields with
+// unit separators, so that subjects and bodies can contain any text.
+const logFormat = "--format=%x1e%H%x1f%an%x1f%ae%x1f%ad%x1f%s%x1f%b%x1f"
+
+// ShortHash returns the abbreviated commit hash
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// String formats the commit in a git log like layout suitable for prompts
+func (c Commit) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "commit %s\nAuthor: %s <%s>\nDate: %s\n\n    %s\n", c.Hash, c.Author, c.Email, c.Date, c.Subject)
+	if c.Body != "" {
+		for _, line := range strings.Split(c.Body, "\n") {
+			sb.WriteString("    " + line + "\n")
+		}
+	}
+	if c.Extra != "" {
+		sb.WriteString("\n" + c.Extra + "\n")
+	}
+	return sb.String()
+}
+
+// parseLog parses output produced with logFormat into commits
+func parseLog(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 7)
+		if len(fields) < 6 {
+			continue
+		}
+		commit := Commit{
+			Hash:    strings.TrimSpace(fields[0]),
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    fields[3],
+			Subject: fields[4],
+			Body:    strings.TrimSpace(fields[5]),
+		}
+		if len(fields) == 7 {
+			commit.Extra = strings.TrimSpace(fields[6])
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// GetLineHistory returns the commits that touched the given line range of a file,
+// newest first, using "git log -L". Each commit's Extra field holds the patch
+// restricted to that range.
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - path: The file path, relative to repoPath
+//   - start, end: The 1-based inclusive line range
+//   - maxCount: The maximum number of commits to return, 0 means no limit
+//
+// Returns:
+//   - []Commit: The commits that shaped the line range
+//   - error: An error if the git command fails, e.g. the range is out of bounds
+func (g *GitVCS) GetLineHistory(repoPath, path string, start, end, maxCount int) ([]Commit, error) {
+	args := []string{"log", "--no-color", "--date=short", logFormat}
+	if maxCount > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", maxCount))
+	}
+	args = append(args, fmt.Sprintf("-L%d,%d:%s", start, end, path))
+
+	output, err := g.runCommand(exec.Command("git", args...), repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(output), nil
+}
+
+// GetUserEmail returns the user.email configured for the git repository
+func (g *GitVCS) GetUserEmail(repoPath string) (string, error) {
+	output, err := g.runCommand(exec.Command("git", "config", "user.email"), repoPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// GetConfigValue returns the value of key in the git config of the repository,
+// empty if it is not set
+func (g *GitVCS) GetConfigValue(repoPath, key string) (string, error) {
+	output, err := g.runCommand(exec.Command("git", "config", "--get", "--default", "", key), repoPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// GetCommitsSince returns the commits on all branches created after since,
+// newest first, excluding merges.
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - since: Any date accepted by "git log --since", e.g. "yesterday" or "2025-01-01"
+//   - author: Only include commits whose author matches this pattern, empty means everyone
+//
+// Returns:
+//   - []Commit: The matching commits
+//   - error: An error if the git command fails
+func (g *GitVCS) GetCommitsSince(repoPath, since, author string) ([]Commit, error) {
+	args := []string{"log", "--all", "--no-merges", "--no-color", "--date=short", logFormat}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	if author != "" {
+		args = append(args, "--author="+author)
+	}
+
+	output, err := g.runCommand(exec.Command("git", args...), repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(output), nil
+}
+
+// GetCommitsWithFiles returns the commits reachable from revRange, newest first,
+// with the paths each commit touched stored one per line in Extra.
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - revRange: A revision or range accepted by git log, e.g. "HEAD" or "abc123..HEAD"
+//
+// Returns:
+//   - []Commit: The matching commits
+//   - error: An error if the git command fails, e.g. the revision does not exist
+func (g *GitVCS) GetCommitsWithFiles(repoPath, revRange string) ([]Commit, error) {
+	cmd := exec.Command("git", "log", "--no-color", "--date=short", "--name-only", logFormat, revRange, "--")
+	output, err := g.runCommand(cmd, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(output), nil
+}
+
+// IsAncestor reports whether the commit ancestor is reachable from the commit
+// descendant, using "git merge-base --is-ancestor".
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - ancestor, descendant: Revisions accepted by git, e.g. a hash or "HEAD"
+//
+// Returns:
+//   - bool: true if ancestor is an ancestor of descendant or the same commit
+//   - error: An error if the git command fails, e.g. a revision does not exist
+func (g *GitVCS) IsAncestor(repoPath, ancestor, descendant string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, descendant)
+	_, err := g.runCommand(cmd, repoPath)
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) && exitError.ExitCode() == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetRecentCommits returns the latest commits of the current branch, newest first,
+// excluding merges.
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - maxCount: The maximum number of commits to return
+//   - paths: Only include commits touching these paths, none means every commit
+//
+// Returns:
+//   - []Commit: The latest commits
+//   - error: An error if the git command fails, e.g. the repository has no commits yet
+func (g *GitVCS) GetRecentCommits(repoPath string, maxCount int, paths ...string) ([]Commit, error) {
+	args := []string{"log", "--no-merges", "--no-color", "--date=short", logFormat, fmt.Sprintf("--max-count=%d", maxCount)}
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, paths...)
+	}
+
+	output, err := g.runCommand(exec.Command("git", args...), repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(output), nil
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLog(t *testing.T) {
+	output := "\x1eabcdef1234567\x1fAlice\x1falice@example.com\x1f2025-01-02\x1ffeat: add x\x1fbody line\x1f\n\ndiff --git a/x b/x\n" +
+		"\x1e1234567abcdef\x1fBob\x1fbob@example.com\x1f2025-01-01\x1finit\x1f\x1f\n"
+
+	commits := parseLog(output)
+	require.Len(t, commits, 2)
+
+	assert.Equal(t, "abcdef1234567", commits[0].Hash)
+	assert.Equal(t, "abcdef1", commits[0].ShortHash())
+	assert.Equal(t, "Alice", commits[0].Author)
+	assert.Equal(t, "feat: add x", commits[0].Subject)
+	assert.Equal(t, "body line", commits[0].Body)
+	assert.Equal(t, "diff --git a/x b/x", commits[0].Extra)
+
+	assert.Equal(t, "init", commits[1].Subject)
+	assert.Empty(t, commits[1].Body)
+	assert.Empty(t, commits[1].Extra)
+}
+
+func TestGetLineHistory(t *testing.T) {
+	_, dir, cleanup := setupVCSTest(t, Git)
+	defer cleanup()
+	vcs := &GitVCS{}
+
+	file := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("line1\nline2\nline3\n"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "add", "main.go"))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "-m", "add main"))
+
+	require.NoError(t, os.WriteFile(file, []byte("line1\nchanged\nline3\n"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "-am", "fix: change line two", "-m", "because of reasons"))
+
+	commits, err := vcs.GetLineHistory(dir, "main.go", 2, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "fix: change line two", commits[0].Subject)
+	assert.Equal(t, "because of reasons", commits[0].Body)
+	assert.Contains(t, commits[0].Extra, "+changed")
+	assert.Equal(t, "add main", commits[1].Subject)
+
+	commits, err = vcs.GetLineHistory(dir, "main.go", 2, 2, 1)
+	require.NoError(t, err)
+	assert.Len(t, commits, 1)
+
+	_, err = vcs.GetLineHistory(dir, "main.go", 10, 20, 0)
+	assert.Error(t, err)
+}
+
+func TestGetRecentCommits(t *testing.T) {
+	_, dir, cleanup := setupVCSTest(t, Git)
+	defer cleanup()
+	vcs := &GitVCS{}
+
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+		require.NoError(t, testutils.RunGitCommand(t, dir, "add", name))
+		require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "-m", "add "+name))
+	}
+
+	commits, err := vcs.GetRecentCommits(dir, 2)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "add c.go", commits[0].Subject)
+	assert.Equal(t, "add b.go", commits[1].Subject)
+
+	commits, err = vcs.GetRecentCommits(dir, 3, "a.go")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "add a.go", commits[0].Subject)
+}
+
+func TestIsAncestor(t *testing.T) {
+	_, dir, cleanup := setupVCSTest(t, Git)
+	defer cleanup()
+	vcs := &GitVCS{}
+
+	require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "--allow-empty", "-m", "first"))
+	first, err := vcs.GetLastCommitHash(dir)
+	require.NoError(t, err)
+	first = strings.TrimSpace(first)
+	require.NoError(t, testutils.RunGitCommand(t, dir, "commit", "--allow-empty", "-m", "second"))
+
+	ok, err := vcs.IsAncestor(dir, first, "HEAD")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = vcs.IsAncestor(dir, "HEAD", first)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = vcs.IsAncestor(dir, "0000000000000000000000000000000000000000", "HEAD")
+	assert.Error(t, err)
+}
+
+func TestGetConfigValue(t *testing.T) {
+	_, dir, cleanup := setupVCSTest(t, Git)
+	defer cleanup()
+	vcs := &GitVCS{}
+
+	value, err := vcs.GetConfigValue(dir, "gptcomet.profile")
+	require.NoError(t, err)
+	assert.Empty(t, value)
+
+	require.NoError(t, testutils.RunGitCommand(t, dir, "config", "gptcomet.profile", "kernel"))
+	value, err = vcs.GetConfigValue(dir, "gptcomet.profile")
+	require.NoError(t, err)
+	assert.Equal(t, "kernel", value)
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/debug"
+)
+
+// Stash represents a stash entry
+type Stash struct {
+	// Ref is the stash reference, e.g. stash@{0}
+	Ref     string
+	Hash    string
+	Date    string
+	Message string
+}
+
+// IsDescriptive reports whether the stash was saved with a custom message.
+// Stashes created without -m are named "WIP on <branch>: <commit subject>".
+func (s Stash) IsDescriptive() bool {
+	return !strings.HasPrefix(s.Message, "WIP on ")
+}
+
+// GetWorkingDiff returns the diff of both staged and unstaged changes against HEAD,
+// which is what "git stash push" saves, excluding files that match ignorePatterns
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//   - ignorePatterns: The patterns of the files left out of the diff
+//
+// Returns:
+//   - string: The diff output
+//   - error: An error if the git command fails or the repository has no commits yet
+func (g *GitVCS) GetWorkingDiff(repoPath string, ignorePatterns []string) (string, error) {
+	if _, err := g.runCommand(exec.Command("git", "rev-parse", "-q", "--verify", "HEAD"), repoPath); err != nil {
+		return "", fmt.Errorf("the repository has no commits yet, there is nothing to stash against")
+	}
+	return g.diffIgnoring(repoPath, ignorePatterns, "HEAD")
+}
+
+// StashPush stashes the local changes with the given message
+func (g *GitVCS) StashPush(repoPath string, message string) error {
+	cmd := exec.Command("git", "stash", "push", "-m", message)
+	_, err := g.runCommand(cmd, repoPath)
+	return err
+}
+
+// ListStashes returns the stash entries, newest first
+//
+// Parameters:
+//   - repoPath: The file system path to the git repository
+//
+// Returns:
+//   - []Stash: The stash entries, or nil if there are none
+//   - error: An error if the git command fails
+func (g *GitVCS) ListStashes(repoPath string) ([]Stash, error) {
+	cmd := exec.Command("git", "stash", "list", "--format=%gd%x1f%H%x1f%cr%x1f%gs")
+	output, err := g.runCommand(cmd, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		stashes = append(stashes, Stash{
+			Ref:     fields[0],
+			Hash:    fields[1],
+			Date:    fields[2],
+			Message: fields[3],
+		})
+	}
+	return stashes, nil
+}
+
+// GetStashDiff returns the patch saved in the given stash entry, excluding files that
+// match ignorePatterns
+func (g *GitVCS) GetStashDiff(repoPath string, ref string, ignorePatterns []string) (string, error) {
+	// The first parent of a stash entry is the commit it was created on
+	return g.diffIgnoring(repoPath, ignorePatterns, ref+"^1", ref)
+}
+
+// diffIgnoring returns the diff between revs, as given to "git diff", of the files
+// that do not match ignorePatterns
+func (g *GitVCS) diffIgnoring(repoPath string, ignorePatterns []string, revs ...string) (string, error) {
+	args := append([]string{"diff", "--name-only"}, revs...)
+	output, err := g.runCommand(exec.Command("git", args...), repoPath)
+	if err != nil {
+		return "", err
+	}
+	var files []string
+	if output = strings.TrimSpace(output); output != "" {
+		files = strings.Split(output, "\n")
+	}
+	files = filterIgnored(files, ignorePatterns)
+	debug.Printf("Filtered files: %v", files)
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	// The listed paths are relative to the repository root, whatever the directory of repoPath
+	args = append(append([]string{"diff", "-U2"}, revs...), "--")
+	for _, file := range files {
+		args = append(args, ":(top,literal)"+file)
+	}
+	return g.runCommand(exec.Command("git", args...), repoPath)
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStash(t *testing.T) {
+	_, dir, cleanup := setupVCSTest(t, Git)
+	defer cleanup()
+	vcs := &GitVCS{}
+
+	// Without a commit there is nothing to stash against
+	_, err := vcs.GetWorkingDiff(dir, nil)
+	assert.ErrorContains(t, err, "no commits yet")
+
+	file := filepath.Join(dir, "main.go")
+	lock := filepath.Join(dir, "go.sum")
+	require.NoError(t, os.WriteFile(file, []byte("one\n"), 0644))
+	require.NoError(t, os.WriteFile(lock, []byte("one\n"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "add", "main.go", "go.sum"))
+	require.NoError(t, vcs.CreateCommit(dir, "init"))
+	ignore := []string{"go.sum"}
+
+	stashes, err := vcs.ListStashes(dir)
+	require.NoError(t, err)
+	assert.Empty(t, stashes)
+
+	// Unstaged change stashed without a message
+	require.NoError(t, os.WriteFile(file, []byte("two\n"), 0644))
+	require.NoError(t, os.WriteFile(lock, []byte("two\n"), 0644))
+	diff, err := vcs.GetWorkingDiff(dir, ignore)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+two")
+	assert.NotContains(t, diff, "go.sum")
+	require.NoError(t, testutils.RunGitCommand(t, dir, "stash"))
+
+	// Staged change stashed with a message
+	require.NoError(t, os.WriteFile(file, []byte("three\n"), 0644))
+	require.NoError(t, testutils.RunGitCommand(t, dir, "add", "main.go"))
+	// Paths are relative to the root when run from a subdirectory
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0755))
+	diff, err = vcs.GetWorkingDiff(sub, ignore)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+three")
+	require.NoError(t, vcs.StashPush(dir, "Rework main output"))
+
+	stashes, err = vcs.ListStashes(dir)
+	require.NoError(t, err)
+	require.Len(t, stashes, 2)
+	assert.Equal(t, "stash@{0}", stashes[0].Ref)
+	assert.Contains(t, stashes[0].Message, "Rework main output")
+	assert.True(t, stashes[0].IsDescriptive())
+	assert.Equal(t, "stash@{1}", stashes[1].Ref)
+	assert.False(t, stashes[1].IsDescriptive())
+	assert.NotEmpty(t, stashes[1].Hash)
+
+	diff, err = vcs.GetStashDiff(dir, stashes[1].Ref, nil)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+two")
+	assert.Contains(t, diff, "go.sum")
+	diff, err = vcs.GetStashDiff(dir, stashes[1].Ref, ignore)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+two")
+	assert.NotContains(t, diff, "go.sum")
+}
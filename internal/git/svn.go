@@ -43,11 +43,22 @@ func (s *SVNVCS) GetStagedFiles(repoPath string) ([]string, error) {
 }
 
 func (s *SVNVCS) GetStagedDiffFiltered(repoPath string, cfgManager *config.Manager) (string, error) {
-	files, err := s.GetStagedFiles(repoPath)
+	return s.GetStagedDiffIgnoring(repoPath, IgnorePatterns(cfgManager))
+}
+
+// GetStagedDiffIgnoring returns the diff of the changed files, excluding files that match ignorePatterns
+func (s *SVNVCS) GetStagedDiffIgnoring(repoPath string, ignorePatterns []string) (string, error) {
+	changed, err := s.GetStagedFiles(repoPath)
 	if err != nil {
 		return "", err
 	}
 
+	var files []string
+	for _, file := range changed {
+		if !ShouldIgnoreFile(file, ignorePatterns) {
+			files = append(files, file)
+		}
+	}
 	if len(files) == 0 {
 		return "", nil
 	}
@@ -111,6 +122,28 @@ func (s *SVNVCS) CreateCommit(repoPath, message string) error {
 	return err
 }
 
+func (s *SVNVCS) GetConflictedFiles(repoPath string) ([]string, error) {
+	cmd := exec.Command("svn", "status")
+	output, err := s.runCommand(cmd, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) > 7 && line[0] == 'C' {
+			files = append(files, strings.TrimSpace(line[7:]))
+		}
+	}
+	return files, nil
+}
+
+func (s *SVNVCS) StageFiles(repoPath string, files []string) error {
+	args := append([]string{"resolve", "--accept", "working"}, files...)
+	_, err := s.runCommand(exec.Command("svn", args...), repoPath)
+	return err
+}
+
 // runCommand 执行命令并返回输出
 func (s *SVNVCS) runCommand(cmd *exec.Cmd, repoPath string) (string, error) {
 	cmd.Dir = repoPath
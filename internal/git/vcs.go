@@ -20,6 +20,8 @@ type VCS interface {
 	GetCommitInfo(repoPath, commitHash string) (string, error)
 	GetLastCommitHash(repoPath string) (string, error)
 	CreateCommit(repoPath, message string) error
+	GetConflictedFiles(repoPath string) ([]string, error)
+	StageFiles(repoPath string, files []string) error
 }
 
 // NewVCS creates a new VCS instance based on the type
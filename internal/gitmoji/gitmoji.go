@@ -0,0 +1,131 @@
+// Package gitmoji converts the gitmojis of commit messages between their :code: and unicode forms.
+package gitmoji
+
+import (
+	"sort"
+	"strings"
+)
+
+// variationSelector makes the preceding character render as an emoji. Models often
+// leave it out, so emojis are also recognized without it.
+const variationSelector = "\uFE0F"
+
+// Emojis maps the codes of the gitmoji list to their unicode form
+var Emojis = map[string]string{
+	":adhesive_bandage:":          "🩹",
+	":airplane:":                  "✈️",
+	":alembic:":                   "⚗️",
+	":alien:":                     "👽️",
+	":ambulance:":                 "🚑️",
+	":arrow_down:":                "⬇️",
+	":arrow_up:":                  "⬆️",
+	":art:":                       "🎨",
+	":beers:":                     "🍻",
+	":bento:":                     "🍱",
+	":bookmark:":                  "🔖",
+	":boom:":                      "💥",
+	":bricks:":                    "🧱",
+	":bug:":                       "🐛",
+	":building_construction:":     "🏗️",
+	":bulb:":                      "💡",
+	":busts_in_silhouette:":       "👥",
+	":camera_flash:":              "📸",
+	":card_file_box:":             "🗃️",
+	":chart_with_upwards_trend:":  "📈",
+	":children_crossing:":         "🚸",
+	":closed_lock_with_key:":      "🔐",
+	":clown_face:":                "🤡",
+	":coffin:":                    "⚰️",
+	":construction:":              "🚧",
+	":construction_worker:":       "👷",
+	":dizzy:":                     "💫",
+	":egg:":                       "🥚",
+	":fire:":                      "🔥",
+	":globe_with_meridians:":      "🌐",
+	":goal_net:":                  "🥅",
+	":green_heart:":               "💚",
+	":hammer:":                    "🔨",
+	":heavy_minus_sign:":          "➖",
+	":heavy_plus_sign:":           "➕",
+	":iphone:":                    "📱",
+	":label:":                     "🏷️",
+	":lipstick:":                  "💄",
+	":lock:":                      "🔒️",
+	":loud_sound:":                "🔊",
+	":mag:":                       "🔍️",
+	":memo:":                      "📝",
+	":money_with_wings:":          "💸",
+	":monocle_face:":              "🧐",
+	":mute:":                      "🔇",
+	":necktie:":                   "👔",
+	":package:":                   "📦️",
+	":page_facing_up:":            "📄",
+	":passport_control:":          "🛂",
+	":pencil2:":                   "✏️",
+	":poop:":                      "💩",
+	":pushpin:":                   "📌",
+	":recycle:":                   "♻️",
+	":rewind:":                    "⏪️",
+	":rocket:":                    "🚀",
+	":rotating_light:":            "🚨",
+	":safety_vest:":               "🦺",
+	":see_no_evil:":               "🙈",
+	":seedling:":                  "🌱",
+	":sparkles:":                  "✨",
+	":speech_balloon:":            "💬",
+	":stethoscope:":               "🩺",
+	":tada:":                      "🎉",
+	":technologist:":              "🧑‍💻",
+	":test_tube:":                 "🧪",
+	":thread:":                    "🧵",
+	":triangular_flag_on_post:":   "🚩",
+	":truck:":                     "🚚",
+	":twisted_rightwards_arrows:": "🔀",
+	":wastebasket:":               "🗑️",
+	":wheelchair:":                "♿️",
+	":white_check_mark:":          "✅",
+	":wrench:":                    "🔧",
+	":zap:":                       "⚡️",
+}
+
+var (
+	toUnicode *strings.Replacer
+	toCode    *strings.Replacer
+)
+
+func init() {
+	codes := make([]string, 0, len(Emojis))
+	for code := range Emojis {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var unicodePairs, codePairs, barePairs []string
+	for _, code := range codes {
+		emoji := Emojis[code]
+		unicodePairs = append(unicodePairs, code, emoji)
+		codePairs = append(codePairs, emoji, code)
+		if bare := strings.ReplaceAll(emoji, variationSelector, ""); bare != emoji {
+			barePairs = append(barePairs, bare, code)
+		}
+	}
+	toUnicode = strings.NewReplacer(unicodePairs...)
+	// The full forms are tried before the forms without variation selector
+	toCode = strings.NewReplacer(append(codePairs, barePairs...)...)
+}
+
+// ToUnicode replaces the gitmoji codes of text with their unicode form
+func ToUnicode(text string) string {
+	return toUnicode.Replace(text)
+}
+
+// ToCode replaces the gitmojis of text with their code
+func ToCode(text string) string {
+	return toCode.Replace(text)
+}
+
+// IsGitmoji reports whether s is a gitmoji, as a code or in unicode
+func IsGitmoji(s string) bool {
+	_, ok := Emojis[ToCode(s)]
+	return ok
+}
@@ -0,0 +1,23 @@
+package gitmoji
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	assert.Equal(t, "✨ feat: add login\n\n- 🐛 fix :unknown:", ToUnicode(":sparkles: feat: add login\n\n- :bug: fix :unknown:"))
+	assert.Equal(t, ":sparkles: feat: add login", ToCode("✨ feat: add login"))
+	// Emojis are recognized with and without variation selector
+	assert.Equal(t, ":zap: perf: cache", ToCode("⚡️ perf: cache"))
+	assert.Equal(t, ":zap: perf: cache", ToCode("⚡ perf: cache"))
+	assert.Equal(t, "⚡️ perf: cache", ToUnicode(ToCode("⚡ perf: cache")))
+}
+
+func TestIsGitmoji(t *testing.T) {
+	assert.True(t, IsGitmoji(":bug:"))
+	assert.True(t, IsGitmoji("♻"))
+	assert.False(t, IsGitmoji(":unknown:"))
+	assert.False(t, IsGitmoji("fix:"))
+}
@@ -0,0 +1,189 @@
+package history
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/debug"
+)
+
+// FileName is the name of the history file in the config directory
+const FileName = "history.jsonl"
+
+// Status is the outcome of a generated message
+type Status string
+
+const (
+	// StatusGenerated means the message was generated but not acted on yet,
+	// e.g. with --dry-run or when the process was interrupted
+	StatusGenerated Status = "generated"
+	// StatusAccepted means the message was committed as generated
+	StatusAccepted Status = "accepted"
+	// StatusEdited means the message was committed after editing
+	StatusEdited Status = "edited"
+	// StatusRejected means the message was declined or regenerated
+	StatusRejected Status = "rejected"
+	// StatusFailed means creating the commit failed, e.g. a pre-commit hook aborted it
+	StatusFailed Status = "failed"
+)
+
+// Entry is a generated commit message and what became of it
+type Entry struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Repo     string    `json:"repo"`
+	DiffHash string    `json:"diff_hash"`
+	Profile  string    `json:"profile,omitempty"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	// Message is the message as generated
+	Message string `json:"message"`
+	// Final is the message after editing, empty if it was not edited
+	Final      string `json:"final,omitempty"`
+	Status     Status `json:"status"`
+	CommitHash string `json:"commit_hash,omitempty"`
+}
+
+// Text returns the message to commit: the edited message if any, or the generated one
+func (e Entry) Text() string {
+	if e.Final != "" {
+		return e.Final
+	}
+	return e.Message
+}
+
+// Subject returns the first line of the message
+func (e Entry) Subject() string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(e.Text()), "\n", 2)[0])
+}
+
+// HashDiff returns a short hash identifying a diff
+func HashDiff(diff string) string {
+	sum := sha256.Sum256([]byte(diff))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Store is an append-only JSONL file of history entries.
+// Updating an entry appends a new version of it, and the last version wins when reading.
+type Store struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store saved at path
+func NewStore(path string) *Store {
+	return &Store{Path: path}
+}
+
+// Add saves a new entry, assigning its ID and time
+func (s *Store) Add(entry *Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.ID == "" {
+		sum := sha1.Sum([]byte(fmt.Sprintf("%d%s%s", entry.Time.UnixNano(), entry.Repo, entry.Message)))
+		entry.ID = hex.EncodeToString(sum[:])[:8]
+	}
+	return s.Update(*entry)
+}
+
+// Update saves a new version of entry
+func (s *Store) Update(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// List returns the latest version of every entry, newest first.
+// If repo is not empty, only entries of that repository are returned.
+func (s *Store) List(repo string) ([]Entry, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	latest := make(map[string]Entry)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			debug.Printf("Skipping malformed history line: %v", err)
+			continue
+		}
+		latest[entry.ID] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(latest))
+	for _, entry := range latest {
+		if repo == "" || entry.Repo == repo {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// Get returns the entry whose ID starts with prefix
+func (s *Store) Get(prefix string) (Entry, error) {
+	entries, err := s.List("")
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var matches []Entry
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.ID, prefix) {
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Entry{}, fmt.Errorf("history entry not found: %s", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return Entry{}, fmt.Errorf("history entry %s is ambiguous, %d entries match", prefix, len(matches))
+	}
+}
@@ -0,0 +1,64 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+
+	entries, err := store.List("")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	first := &Entry{
+		Time:     time.Now().Add(-time.Minute),
+		Repo:     "/src/api",
+		DiffHash: HashDiff("diff --git a/a b/a"),
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Message:  "feat: add login\n\nbody",
+		Status:   StatusGenerated,
+	}
+	require.NoError(t, store.Add(first))
+	assert.Len(t, first.ID, 8)
+
+	second := &Entry{Repo: "/src/web", Message: "fix: typo", Status: StatusGenerated}
+	require.NoError(t, store.Add(second))
+
+	// Updates append a new version that replaces the old one
+	first.Status = StatusEdited
+	first.Final = "feat: add login form"
+	first.CommitHash = "abc123"
+	require.NoError(t, store.Update(*first))
+
+	entries, err = store.List("")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, second.ID, entries[0].ID, "newest first")
+	assert.Equal(t, StatusEdited, entries[1].Status)
+	assert.Equal(t, "feat: add login form", entries[1].Text())
+	assert.Equal(t, "feat: add login form", entries[1].Subject())
+
+	entries, err = store.List("/src/api")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	got, err := store.Get(first.ID[:4])
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", got.CommitHash)
+
+	_, err = store.Get("zzzz")
+	assert.Error(t, err)
+}
+
+func TestHashDiff(t *testing.T) {
+	assert.Len(t, HashDiff("a"), 12)
+	assert.Equal(t, HashDiff("a"), HashDiff("a"))
+	assert.NotEqual(t, HashDiff("a"), HashDiff("b"))
+}
@@ -173,9 +173,7 @@ func (g *GeminiLLM) MakeRequest(ctx context.Context, client *http.Client, messag
 	if err != nil {
 		return "", fmt.Errorf("failed to get usage: %w", err)
 	}
-	if usage != "" {
-		fmt.Printf("%s\n", usage)
-	}
+	printUsage(usage)
 
 	return g.ParseResponse(respBody)
 }
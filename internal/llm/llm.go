@@ -246,9 +246,7 @@ func (b *BaseLLM) MakeRequest(ctx context.Context, client *http.Client, provider
 	if err != nil {
 		return "", fmt.Errorf("failed to get usage: %w", err)
 	}
-	if usage != "" {
-		fmt.Printf("%s\n", usage)
-	}
+	printUsage(usage)
 
 	return provider.ParseResponse(respBody)
 }
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/belingud/go-gptcomet/internal/debug"
+)
+
+// ModelLister is implemented by providers that can list the models available to the account
+type ModelLister interface {
+	// ListModels returns the sorted names of the available models
+	ListModels(ctx context.Context, client *http.Client) ([]string, error)
+}
+
+// fetchModels sends a GET request to url and collects the model names found in the
+// JSON response at the first of paths that exists
+func fetchModels(ctx context.Context, client *http.Client, url string, headers map[string]string, paths ...string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	debug.Printf("Listing models: %s", url)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	for _, path := range paths {
+		result := gjson.GetBytes(respBody, path)
+		if !result.Exists() {
+			continue
+		}
+		var models []string
+		for _, name := range result.Array() {
+			if name.String() != "" {
+				models = append(models, name.String())
+			}
+		}
+		sort.Strings(models)
+		return models, nil
+	}
+	return nil, fmt.Errorf("failed to parse models from response: %s", string(respBody))
+}
+
+// ListModels lists models from the OpenAI compatible /models endpoint
+func (o *OpenAILLM) ListModels(ctx context.Context, client *http.Client) ([]string, error) {
+	url := strings.TrimSuffix(o.Config.APIBase, "/") + "/models"
+	return fetchModels(ctx, client, url, o.BuildHeaders(), "data.#.id", "models.#.name", "models.#.id")
+}
+
+// ListModels lists models from the Anthropic /v1/models endpoint
+func (c *ClaudeLLM) ListModels(ctx context.Context, client *http.Client) ([]string, error) {
+	url := strings.TrimSuffix(c.Config.APIBase, "/") + "/models"
+	return fetchModels(ctx, client, url, c.BuildHeaders(), "data.#.id")
+}
+
+// ListModels lists models from the Gemini models endpoint
+func (g *GeminiLLM) ListModels(ctx context.Context, client *http.Client) ([]string, error) {
+	url := strings.TrimSuffix(g.Config.APIBase, "/") + "?pageSize=1000"
+	// The key is sent in a header rather than the query, so it stays out of logged URLs
+	headers := g.BuildHeaders()
+	headers["x-goog-api-key"] = g.Config.APIKey
+	models, err := fetchModels(ctx, client, url, headers, "models.#.name")
+	if err != nil {
+		return nil, err
+	}
+	for i, model := range models {
+		models[i] = strings.TrimPrefix(model, "models/")
+	}
+	return models, nil
+}
+
+// ListModels lists the locally pulled models from the Ollama /api/tags endpoint
+func (o *OllamaLLM) ListModels(ctx context.Context, client *http.Client) ([]string, error) {
+	url := strings.TrimSuffix(o.Config.APIBase, "/") + "/tags"
+	return fetchModels(ctx, client, url, o.BuildHeaders(), "models.#.name")
+}
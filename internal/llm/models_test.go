@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openai/models":
+			assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"data":[{"id":"gpt-4o-mini"},{"id":"gpt-4o"}]}`))
+		case "/claude/models":
+			assert.Equal(t, "key", r.Header.Get("x-api-key"))
+			w.Write([]byte(`{"data":[{"id":"claude-3-5-sonnet-latest"}]}`))
+		case "/gemini":
+			assert.Equal(t, "key", r.Header.Get("x-goog-api-key"))
+			assert.Empty(t, r.URL.Query().Get("key"))
+			w.Write([]byte(`{"models":[{"name":"models/gemini-1.5-pro"},{"name":"models/gemini-1.5-flash"}]}`))
+		case "/ollama/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		lister  ModelLister
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "openai",
+			lister: NewOpenAILLM(&types.ClientConfig{APIBase: server.URL + "/openai", APIKey: "key"}),
+			want:   []string{"gpt-4o", "gpt-4o-mini"},
+		},
+		{
+			name:   "claude",
+			lister: NewClaudeLLM(&types.ClientConfig{APIBase: server.URL + "/claude", APIKey: "key"}),
+			want:   []string{"claude-3-5-sonnet-latest"},
+		},
+		{
+			name:   "gemini",
+			lister: NewGeminiLLM(&types.ClientConfig{APIBase: server.URL + "/gemini", APIKey: "key"}),
+			want:   []string{"gemini-1.5-flash", "gemini-1.5-pro"},
+		},
+		{
+			name:   "ollama",
+			lister: NewOllamaLLM(&types.ClientConfig{APIBase: server.URL + "/ollama"}),
+			want:   []string{"llama3:latest"},
+		},
+		{
+			name:    "error status",
+			lister:  NewOpenAILLM(&types.ClientConfig{APIBase: server.URL + "/missing", APIKey: "key"}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models, err := tt.lister.ListModels(context.Background(), server.Client())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, models)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+)
+
+// usageOutput receives the token usage of each request, nil to discard it
+var usageOutput io.Writer
+
+// SetUsageOutput sets where the token usage of each request is printed.
+// Nothing is printed by default, so the package can be used as a library.
+func SetUsageOutput(w io.Writer) {
+	usageOutput = w
+}
+
+// printUsage prints the token usage of a request, if an output is set
+func printUsage(usage string) {
+	if usageOutput != nil && usage != "" {
+		fmt.Fprintln(usageOutput, usage)
+	}
+}
@@ -0,0 +1,21 @@
+package llm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintUsage(t *testing.T) {
+	defer SetUsageOutput(nil)
+
+	// Nothing is printed without an output
+	printUsage("Token usage> prompt: 1, completion: 2, total: 3")
+
+	var buf bytes.Buffer
+	SetUsageOutput(&buf)
+	printUsage("")
+	printUsage("Token usage> prompt: 1, completion: 2, total: 3")
+	assert.Equal(t, "Token usage> prompt: 1, completion: 2, total: 3\n", buf.String())
+}
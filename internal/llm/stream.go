@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/pkg/types"
+)
+
+// ChunkStreamer is implemented by providers whose API streams OpenAI compatible chat
+// completion chunks when the request sets "stream", see StreamChat
+type ChunkStreamer interface {
+	LLM
+	// StreamsChunks reports whether the provider can stream its answers
+	StreamsChunks() bool
+}
+
+// StreamsChunks reports that OpenAI compatible providers stream their answers
+func (o *OpenAILLM) StreamsChunks() bool {
+	return true
+}
+
+// StreamChat sends message to provider with streaming enabled, calls onDelta with every
+// chunk of the answer as it arrives and returns the whole answer with the reported usage
+func StreamChat(ctx context.Context, client *http.Client, provider ChunkStreamer, message string, history []types.Message, onDelta func(string)) (string, *types.Usage, error) {
+	payload, err := provider.FormatMessages(message, history)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to format messages: %w", err)
+	}
+	body, ok := payload.(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("provider %s does not support streaming", provider.Name())
+	}
+	body["stream"] = true
+	body["stream_options"] = map[string]interface{}{"include_usage": true}
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", provider.BuildURL(), bytes.NewReader(reqBody))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range provider.BuildHeaders() {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	debug.Printf("Streaming request...")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var (
+		content strings.Builder
+		usage   *types.Usage
+	)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		if chunkUsage := ParseUsage([]byte(data)); chunkUsage != nil {
+			usage = chunkUsage
+		}
+		delta := gjson.Get(data, "choices.0.delta.content").String()
+		if delta == "" {
+			continue
+		}
+		content.WriteString(delta)
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return strings.TrimSpace(content.String()), usage, nil
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"github.com/tidwall/gjson"
+
+	"github.com/belingud/go-gptcomet/pkg/types"
+)
+
+// usageFormat describes where a provider response reports token usage
+type usageFormat struct {
+	path       string
+	prompt     string
+	completion string
+	total      string
+}
+
+// usageFormats lists the usage layouts of the supported providers
+var usageFormats = []usageFormat{
+	// OpenAI compatible providers and Tongyi
+	{path: "usage", prompt: "prompt_tokens", completion: "completion_tokens", total: "total_tokens"},
+	// Claude and Cohere
+	{path: "usage", prompt: "input_tokens", completion: "output_tokens"},
+	// Gemini
+	{path: "usageMetadata", prompt: "promptTokenCount", completion: "candidatesTokenCount", total: "totalTokenCount"},
+	// Vertex
+	{path: "metadata.tokenMetadata", prompt: "inputTokenCount", completion: "outputTokenCount", total: "totalTokenCount"},
+	// Ollama
+	{path: "@this", prompt: "prompt_eval_count", completion: "eval_count"},
+}
+
+// ParseUsage extracts the token usage from a provider response.
+// It returns nil if the response does not report usage.
+func ParseUsage(data []byte) *types.Usage {
+	for _, format := range usageFormats {
+		usage := gjson.GetBytes(data, format.path)
+		if !usage.IsObject() {
+			continue
+		}
+		prompt := usage.Get(format.prompt)
+		completion := usage.Get(format.completion)
+		if !prompt.Exists() && !completion.Exists() {
+			continue
+		}
+
+		result := &types.Usage{
+			PromptTokens:     int(prompt.Int()),
+			CompletionTokens: int(completion.Int()),
+		}
+		if format.total != "" {
+			result.TotalTokens = int(usage.Get(format.total).Int())
+		}
+		if result.TotalTokens == 0 {
+			result.TotalTokens = result.PromptTokens + result.CompletionTokens
+		}
+		return result
+	}
+	return nil
+}
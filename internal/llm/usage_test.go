@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/belingud/go-gptcomet/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want *types.Usage
+	}{
+		{
+			name: "openai",
+			data: `{"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+			want: &types.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		},
+		{
+			name: "claude",
+			data: `{"usage":{"input_tokens":12,"output_tokens":3}}`,
+			want: &types.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+		},
+		{
+			name: "gemini",
+			data: `{"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":2,"totalTokenCount":9}}`,
+			want: &types.Usage{PromptTokens: 7, CompletionTokens: 2, TotalTokens: 9},
+		},
+		{
+			name: "vertex",
+			data: `{"metadata":{"tokenMetadata":{"inputTokenCount":4,"outputTokenCount":4,"totalTokenCount":8}}}`,
+			want: &types.Usage{PromptTokens: 4, CompletionTokens: 4, TotalTokens: 8},
+		},
+		{
+			name: "ollama",
+			data: `{"response":"ok","prompt_eval_count":20,"eval_count":6}`,
+			want: &types.Usage{PromptTokens: 20, CompletionTokens: 6, TotalTokens: 26},
+		},
+		{
+			name: "no usage",
+			data: `{"choices":[]}`,
+		},
+		{
+			name: "not json",
+			data: `data: {"choices":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseUsage([]byte(tt.data)))
+		})
+	}
+}
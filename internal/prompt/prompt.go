@@ -0,0 +1,282 @@
+// Package prompt renders prompt templates.
+//
+// Prompts are text/template templates. For compatibility with older prompts, a
+// variable alone in an action may be written without the leading dot: "{{ diff }}"
+// is "{{ .diff }}" and "{{ output.lang }}" is "{{ .output.lang }}". Variables named
+// after template keywords such as range need the dot. Using a variable that is not
+// defined is an error.
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/belingud/go-gptcomet/internal/committype"
+)
+
+// Vars are the variables of a prompt by name. Dotted names such as "output.lang"
+// are nested, so they can also be used as {{ with .output }}{{ .lang }}{{ end }}.
+type Vars map[string]interface{}
+
+// List is a list variable, rendered one item per line and usable with range
+type List []string
+
+// String returns the items, one per line
+func (l List) String() string {
+	return strings.Join(l, "\n")
+}
+
+// FileHistory is a file and the subjects of the last commits touching it
+type FileHistory struct {
+	Path     string
+	Subjects []string
+}
+
+// History is the commit history of several files, rendered one file per paragraph
+// and usable with range
+type History []FileHistory
+
+// String returns each path followed by its subjects
+func (h History) String() string {
+	var b strings.Builder
+	for i, file := range h {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(file.Path + ":\n")
+		for _, subject := range file.Subjects {
+			b.WriteString("- " + subject + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// UnknownVariableError is returned when a prompt uses a variable that is not defined
+type UnknownVariableError struct {
+	Name  string
+	Known []string
+}
+
+// Error lists the defined variables
+func (e *UnknownVariableError) Error() string {
+	return fmt.Sprintf("unknown prompt variable %q, available variables: %s", e.Name, strings.Join(e.Known, ", "))
+}
+
+// bareVariableRe matches actions holding only a variable name without the leading dot
+var bareVariableRe = regexp.MustCompile(`\{\{(-?)(\s*)([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)(\s*)(-?)\}\}`)
+
+// keywords are the template keywords and constants, actions starting with them stay
+// as they are so that the template reports its own error when they are misused
+var keywords = map[string]bool{
+	"block":    true,
+	"break":    true,
+	"continue": true,
+	"define":   true,
+	"else":     true,
+	"end":      true,
+	"false":    true,
+	"if":       true,
+	"nil":      true,
+	"range":    true,
+	"template": true,
+	"true":     true,
+	"with":     true,
+}
+
+// funcs are the functions available in prompts
+var funcs = template.FuncMap{
+	"join": strings.Join,
+	"trim": strings.TrimSpace,
+}
+
+// shim rewrites the "{{ name }}" actions of text to "{{ .name }}"
+func shim(text string) string {
+	return bareVariableRe.ReplaceAllStringFunc(text, func(action string) string {
+		m := bareVariableRe.FindStringSubmatch(action)
+		if name, _, _ := strings.Cut(m[3], "."); keywords[name] {
+			return action
+		}
+		return "{{" + m[1] + m[2] + "." + m[3] + m[4] + m[5] + "}}"
+	})
+}
+
+// data nests the dotted names of vars
+func data(vars Vars) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	// Shorter names first, so "output" is set before "output.lang" conflicts with it
+	sort.Strings(names)
+
+	for _, name := range names {
+		parts := strings.Split(name, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part]
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			childMap, ok := child.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("prompt variable %q conflicts with %q", name, part)
+			}
+			node = childMap
+		}
+		node[parts[len(parts)-1]] = vars[name]
+	}
+	return root, nil
+}
+
+// check returns an *UnknownVariableError if a field of the template root is not in data.
+// Fields inside range and with are not checked since they are relative to another value.
+func check(node parse.Node, root map[string]interface{}, known []string) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := check(child, root, known); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return check(n.Pipe, root, known)
+	case *parse.IfNode:
+		if err := check(n.Pipe, root, known); err != nil {
+			return err
+		}
+		if err := check(n.List, root, known); err != nil {
+			return err
+		}
+		return check(n.ElseList, root, known)
+	case *parse.RangeNode:
+		if err := check(n.Pipe, root, known); err != nil {
+			return err
+		}
+		return check(n.ElseList, root, known)
+	case *parse.WithNode:
+		if err := check(n.Pipe, root, known); err != nil {
+			return err
+		}
+		return check(n.ElseList, root, known)
+	case *parse.TemplateNode:
+		return check(n.Pipe, root, known)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := check(cmd, root, known); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := check(arg, root, known); err != nil {
+				return err
+			}
+		}
+	case *parse.FieldNode:
+		var value interface{} = root
+		for i, ident := range n.Ident {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				// Fields of other values are checked when executing
+				return nil
+			}
+			if value, ok = m[ident]; !ok {
+				return &UnknownVariableError{Name: strings.Join(n.Ident[:i+1], "."), Known: known}
+			}
+		}
+	}
+	return nil
+}
+
+// Render renders the prompt text with vars
+func Render(text string, vars Vars) (string, error) {
+	tmpl, err := template.New("prompt").Funcs(funcs).Option("missingkey=error").Parse(shim(text))
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	root, err := data(vars)
+	if err != nil {
+		return "", err
+	}
+	known := make([]string, 0, len(vars))
+	for name := range vars {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	if err := check(tmpl.Tree.Root, root, known); err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, root); err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Commit holds the variables of the commit message prompts
+type Commit struct {
+	// Diff is the staged diff, also available as placeholder
+	Diff        string
+	StagedFiles []string
+	Branch      string
+	// Repo is the name of the repository
+	Repo string
+	// Lang is the name of the output language, also available as output.lang
+	Lang string
+	// RichTemplate is the format of rich messages, also available as output.rich_template
+	RichTemplate  string
+	RecentCommits []string
+	// Hint is extra context given by the user
+	Hint string
+	// Style describes the commit conventions of the repository, StyleExamples are
+	// messages following them
+	Style         string
+	StyleExamples []string
+	// RelatedHistory holds the last commits touching each staged file
+	RelatedHistory History
+	// Scopes are the scopes of the staged files, derived from their paths
+	Scopes []string
+	// Types are the commit types the message can be written with
+	Types []committype.Type
+	// OutputStyle is the format of the message: conventional, gitmoji or plain,
+	// also available as output.style
+	OutputStyle string
+}
+
+// Vars returns the variables of c
+func (c Commit) Vars() Vars {
+	return Vars{
+		"placeholder":          c.Diff,
+		"diff":                 c.Diff,
+		"staged_files":         List(c.StagedFiles),
+		"branch":               c.Branch,
+		"repo":                 c.Repo,
+		"lang":                 c.Lang,
+		"output.lang":          c.Lang,
+		"rich_template":        c.RichTemplate,
+		"output.rich_template": c.RichTemplate,
+		"recent_commits":       List(c.RecentCommits),
+		"hint":                 c.Hint,
+		"style":                c.Style,
+		"style_examples":       List(c.StyleExamples),
+		"related_history":      c.RelatedHistory,
+		"scopes":               List(c.Scopes),
+		"commit_types":         c.Types,
+		"output.style":         c.OutputStyle,
+	}
+}
@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/belingud/go-gptcomet/pkg/config/defaults"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderCompatSyntax(t *testing.T) {
+	out, err := Render("Lines {{ lines }} of {{ file }} in {{ output.lang }}:\n{{placeholder}}", Vars{
+		"lines":       "3-5",
+		"file":        "main.go",
+		"output.lang": "French",
+		"placeholder": "history",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Lines 3-5 of main.go in French:\nhistory", out)
+
+	// Keywords and constants are never taken for variables
+	vars := Vars{"range": "3-5", "if": "x", "items": List{"a", "b"}}
+	out, err = Render("{{ if true }}{{ range .items }}{{ . }}{{ end }}{{ end }}{{ with false }}x{{ else }} none{{ end }}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "ab none", out)
+	for _, text := range []string{"{{ range }}", "{{ if }}", "{{ with }}", "{{ template }}", "{{ define }}", "{{ block }}"} {
+		_, err = Render(text, vars)
+		assert.ErrorContains(t, err, "invalid prompt template", text)
+	}
+	// Fields named after keywords are still available with the leading dot
+	out, err = Render("{{ .range }}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "3-5", out)
+}
+
+func TestRenderTemplateSyntax(t *testing.T) {
+	vars := Commit{
+		Diff:          "diff",
+		StagedFiles:   []string{"a.go", "b.go"},
+		Branch:        "feature/login",
+		RecentCommits: []string{"feat: add login", "fix: typo"},
+	}.Vars()
+
+	out, err := Render(`{{ if .hint }}Hint: {{ .hint }}{{ else }}No hint{{ end }}
+{{- range .staged_files }}
+- {{ . }}{{ end }}
+{{ join .recent_commits "; " }}
+{{ recent_commits }}
+{{ with .output }}{{ .lang }}{{ end }}on {{ branch }}`, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "No hint\n- a.go\n- b.go\nfeat: add login; fix: typo\nfeat: add login\nfix: typo\non feature/login", out)
+
+	vars["hint"] = "part of the auth epic"
+	_, err = Render("{{- if hint -}} {{ hint }} {{- end }}", vars)
+	require.Error(t, err, "if needs an argument, not a bare name")
+	out, err = Render("{{- if .hint -}} {{ hint }} {{- end }}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "part of the auth epic", out)
+}
+
+func TestRenderUnknownVariable(t *testing.T) {
+	_, err := Render("{{ diff }} {{ ticket }}", Vars{"diff": "d", "branch": "main"})
+	var unknown *UnknownVariableError
+	require.ErrorAs(t, err, &unknown)
+	assert.Equal(t, "ticket", unknown.Name)
+	assert.EqualError(t, err, `unknown prompt variable "ticket", available variables: branch, diff`)
+
+	// unknown variables are reported even in branches that are not rendered
+	_, err = Render("{{ if .diff }}ok{{ else }}{{ .missing }}{{ end }}", Vars{"diff": "d"})
+	require.ErrorAs(t, err, &unknown)
+	assert.Equal(t, "missing", unknown.Name)
+
+	_, err = Render("{{ output.color }}", Vars{"output.lang": "en"})
+	require.ErrorAs(t, err, &unknown)
+	assert.Equal(t, "output.color", unknown.Name)
+}
+
+func TestRenderErrors(t *testing.T) {
+	_, err := Render("{{ if .diff }}", Vars{"diff": "d"})
+	assert.ErrorContains(t, err, "invalid prompt template")
+
+	_, err = Render("{{ output.lang }}", Vars{"output": "x", "output.lang": "en"})
+	assert.ErrorContains(t, err, "conflicts")
+}
+
+func TestDefaultPromptsRender(t *testing.T) {
+	vars := Commit{Diff: "diff --git a/x b/x", RichTemplate: "<title>:<summary>", Lang: "English"}.Vars()
+	for _, name := range []string{"brief_commit_message", "rich_commit_message", "translation", "review"} {
+		_, err := Render(defaults.PromptDefaults[name], vars)
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	history := History{
+		{Path: "cmd/commit.go", Subjects: []string{"feat(commit): add --hint", "fix(commit): trim output"}},
+		{Path: "go.mod", Subjects: []string{"chore: bump cobra"}},
+	}
+	assert.Equal(t, "cmd/commit.go:\n- feat(commit): add --hint\n- fix(commit): trim output\n\ngo.mod:\n- chore: bump cobra", history.String())
+
+	text, err := Render("{{ range .related_history }}{{ .Path }}={{ join .Subjects \",\" }};{{ end }}", Commit{RelatedHistory: history}.Vars())
+	require.NoError(t, err)
+	assert.Equal(t, "cmd/commit.go=feat(commit): add --hint,fix(commit): trim output;go.mod=chore: bump cobra;", text)
+
+	// The default prompts leave the section out without history
+	text, err = Render(defaults.PromptDefaults["brief_commit_message"], Commit{Diff: "d"}.Vars())
+	require.NoError(t, err)
+	assert.NotContains(t, text, "Recent commits touching the staged files")
+}
@@ -0,0 +1,146 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the JSON-RPC version spoken
+const Version = "2.0"
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeRequestFailed is returned when the provider request failed
+	CodeRequestFailed = -32000
+	// CodeLimitExceeded is returned when a request would exceed a configured limit
+	CodeLimitExceeded = -32001
+	// CodeRequestCancelled is returned when a request was cancelled by the client
+	CodeRequestCancelled = -32800
+)
+
+// maxMessageSize is the maximum size of a message body
+const maxMessageSize = 32 << 20
+
+// Request is a JSON-RPC request, or a notification when ID is empty
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification reports whether the request expects no response
+func (r *Request) IsNotification() bool {
+	return len(r.ID) == 0
+}
+
+// Response is a JSON-RPC response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is a JSON-RPC notification sent to the client
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Error is a JSON-RPC error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errorf creates an error with code and a formatted message
+func Errorf(code int, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conn reads and writes messages framed with LSP style Content-Length headers
+type Conn struct {
+	r *bufio.Reader
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewConn creates a connection reading from r and writing to w
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{r: bufio.NewReader(r), w: w}
+}
+
+// Read reads the next message body. It returns io.EOF when the input is closed.
+func (c *Conn) Read() ([]byte, error) {
+	headers, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || (len(headers) == 0 && err == io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length: %q", headers.Get("Content-Length"))
+	}
+	if length > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the maximum of %d", length, maxMessageSize)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return body, nil
+}
+
+// Write writes v as a message. It is safe to call from several goroutines.
+func (c *Conn) Write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Reply writes the response to the request with id, an error response if err is not nil
+func (c *Conn) Reply(id json.RawMessage, result interface{}, err *Error) error {
+	if err != nil {
+		return c.Write(Response{JSONRPC: Version, ID: id, Error: err})
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	return c.Write(Response{JSONRPC: Version, ID: id, Result: result})
+}
+
+// Notify writes a notification
+func (c *Conn) Notify(method string, params interface{}) error {
+	return c.Write(Notification{JSONRPC: Version, Method: method, Params: params})
+}
@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	conn := NewConn(nil, &buf)
+	require.NoError(t, conn.Reply(json.RawMessage("1"), map[string]string{"content": "ok"}, nil))
+	require.NoError(t, conn.Reply(json.RawMessage(`"a"`), nil, Errorf(CodeMethodNotFound, "method not found: %s", "nope")))
+	require.NoError(t, conn.Notify("$/progress", map[string]string{"message": "working"}))
+
+	assert.True(t, strings.HasPrefix(buf.String(), "Content-Length: 50\r\n\r\n{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"content\":\"ok\"}}"))
+
+	reader := NewConn(&buf, io.Discard)
+	body, err := reader.Read()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"content":"ok"}}`, string(body))
+
+	body, err = reader.Read()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":"a","error":{"code":-32601,"message":"method not found: nope"}}`, string(body))
+
+	body, err = reader.Read()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"$/progress","params":{"message":"working"}}`, string(body))
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestConnReadErrors(t *testing.T) {
+	_, err := NewConn(strings.NewReader("Content-Length: abc\r\n\r\n{}"), io.Discard).Read()
+	assert.ErrorContains(t, err, "invalid Content-Length")
+
+	_, err = NewConn(strings.NewReader("Content-Length: 10\r\n\r\n{}"), io.Discard).Read()
+	assert.ErrorContains(t, err, "failed to read message")
+
+	_, err = NewConn(strings.NewReader(""), io.Discard).Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRequestIsNotification(t *testing.T) {
+	var req Request
+	require.NoError(t, json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"exit"}`), &req))
+	assert.True(t, req.IsNotification())
+	require.NoError(t, json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":0,"method":"shutdown"}`), &req))
+	assert.False(t, req.IsNotification())
+}
@@ -0,0 +1,146 @@
+// Package scope maps the files of a monorepo to conventional commit scopes.
+package scope
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ManifestFiles are the files marking the root directory of a package
+var ManifestFiles = []string{"go.mod", "package.json"}
+
+// subjectRe matches "type(scope)!: summary" subjects, optionally starting with a gitmoji
+var subjectRe = regexp.MustCompile(`^((?::[a-z0-9_+-]+:|[^\x00-\x7F]+) )?([a-zA-Z]+)(?:\([^)]*\))?(!?): (.*)$`)
+
+// Rule gives the files matching Pattern the scope Scope. Patterns are slash separated
+// globs where "**" matches any number of directories, e.g. "services/billing/**".
+type Rule struct {
+	Pattern string
+	Scope   string
+}
+
+// Mapper computes the scope of files from rules, then from package roots
+type Mapper struct {
+	rules []Rule
+	roots []string
+}
+
+// NewMapper creates a mapper. The most specific rule, the longest pattern, wins and
+// files matching no rule get the name of the deepest package root holding them.
+func NewMapper(rules []Rule, roots []string) *Mapper {
+	m := &Mapper{
+		rules: append([]Rule(nil), rules...),
+		roots: append([]string(nil), roots...),
+	}
+	sort.Slice(m.rules, func(i, j int) bool {
+		if len(m.rules[i].Pattern) != len(m.rules[j].Pattern) {
+			return len(m.rules[i].Pattern) > len(m.rules[j].Pattern)
+		}
+		return m.rules[i].Pattern < m.rules[j].Pattern
+	})
+	sort.Slice(m.roots, func(i, j int) bool {
+		return len(m.roots[i]) > len(m.roots[j])
+	})
+	return m
+}
+
+// Roots returns the directories of the manifest files, leaving out the repository root
+func Roots(manifests []string) []string {
+	var roots []string
+	seen := make(map[string]bool)
+	for _, manifest := range manifests {
+		dir := path.Dir(manifest)
+		if dir == "." || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		roots = append(roots, dir)
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// Scope returns the scope of file, empty if it has none
+func (m *Mapper) Scope(file string) string {
+	for _, rule := range m.rules {
+		if Match(rule.Pattern, file) {
+			return rule.Scope
+		}
+	}
+	for _, root := range m.roots {
+		if strings.HasPrefix(file, root+"/") {
+			return path.Base(root)
+		}
+	}
+	return ""
+}
+
+// Scopes returns the sorted scopes of files and the files of each scope.
+// Files without a scope are left out.
+func (m *Mapper) Scopes(files []string) ([]string, map[string][]string) {
+	byScope := make(map[string][]string)
+	var scopes []string
+	for _, file := range files {
+		scope := m.Scope(file)
+		if scope == "" {
+			continue
+		}
+		if _, ok := byScope[scope]; !ok {
+			scopes = append(scopes, scope)
+		}
+		byScope[scope] = append(byScope[scope], file)
+	}
+	sort.Strings(scopes)
+	return scopes, byScope
+}
+
+// Match reports whether the slash separated file matches pattern. "**" matches any
+// number of directories and a trailing slash matches everything below a directory.
+func Match(pattern, file string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// Apply sets the scope of a conventional commit message to the comma-joined scopes,
+// replacing the scope chosen by the model. Other messages are returned unchanged.
+func Apply(message string, scopes []string) string {
+	if len(scopes) == 0 {
+		return message
+	}
+	subject, rest, multiline := strings.Cut(message, "\n")
+	m := subjectRe.FindStringSubmatch(subject)
+	if m == nil {
+		return message
+	}
+	subject = m[1] + m[2] + "(" + strings.Join(scopes, ",") + ")" + m[3] + ": " + m[4]
+	if multiline {
+		return subject + "\n" + rest
+	}
+	return subject
+}
@@ -0,0 +1,56 @@
+package scope
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"services/billing/**", "services/billing/api/handler.go", true},
+		{"services/billing/**", "services/billing", true},
+		{"services/billing/**", "services/billingx/main.go", false},
+		{"services/*/cmd/**", "services/auth/cmd/main.go", true},
+		{"**/*.proto", "api/v1/billing.proto", true},
+		{"**/*.proto", "billing.proto", true},
+		{"docs/", "docs/guide/intro.md", true},
+		{"go.mod", "go.mod", true},
+		{"go.mod", "tools/go.mod", false},
+		{"[", "x", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.pattern, tt.file), "%s ~ %s", tt.pattern, tt.file)
+	}
+}
+
+func TestMapper(t *testing.T) {
+	roots := Roots([]string{"go.mod", "services/billing/go.mod", "web/app/package.json", "web/app/package.json"})
+	assert.Equal(t, []string{"services/billing", "web/app"}, roots)
+
+	m := NewMapper([]Rule{
+		{Pattern: "services/**", Scope: "services"},
+		{Pattern: "services/billing/api/**", Scope: "billing-api"},
+	}, roots)
+	assert.Equal(t, "billing-api", m.Scope("services/billing/api/v1.go"))
+	assert.Equal(t, "services", m.Scope("services/billing/main.go"))
+	assert.Equal(t, "app", m.Scope("web/app/src/index.ts"))
+	assert.Equal(t, "", m.Scope("README.md"))
+
+	scopes, byScope := m.Scopes([]string{"web/app/a.ts", "services/auth/main.go", "README.md", "web/app/b.ts"})
+	assert.Equal(t, []string{"app", "services"}, scopes)
+	assert.Equal(t, []string{"web/app/a.ts", "web/app/b.ts"}, byScope["app"])
+}
+
+func TestApply(t *testing.T) {
+	assert.Equal(t, "fix(billing): round totals", Apply("fix: round totals", []string{"billing"}))
+	assert.Equal(t, "feat(app,billing)!: drop v1\n\n- remove routes", Apply("feat(api)!: drop v1\n\n- remove routes", []string{"app", "billing"}))
+	assert.Equal(t, "Round totals", Apply("Round totals", []string{"billing"}))
+	assert.Equal(t, ":bug: fix(billing): round totals", Apply(":bug: fix: round totals", []string{"billing"}))
+	assert.Equal(t, "🐛 fix(billing): round totals", Apply("🐛 fix(core): round totals", []string{"billing"}))
+	assert.Equal(t, "fix: round totals", Apply("fix: round totals", nil))
+}
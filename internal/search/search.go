@@ -0,0 +1,204 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 tuning parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// stopWords are common words that carry no meaning for ranking
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "did": true, "do": true, "does": true, "for": true,
+	"from": true, "how": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "the": true, "to": true, "was": true, "we": true,
+	"what": true, "when": true, "where": true, "which": true, "who": true,
+	"why": true, "with": true,
+}
+
+// Document is a single indexed commit
+type Document struct {
+	Hash    string   `json:"hash"`
+	Author  string   `json:"author"`
+	Date    string   `json:"date"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body,omitempty"`
+	Files   []string `json:"files,omitempty"`
+}
+
+// String formats the document for use as prompt context
+func (d Document) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "commit %s\nAuthor: %s\nDate: %s\n\n    %s\n", d.Hash, d.Author, d.Date, d.Subject)
+	if d.Body != "" {
+		for _, line := range strings.Split(d.Body, "\n") {
+			sb.WriteString("    " + line + "\n")
+		}
+	}
+	if len(d.Files) > 0 {
+		sb.WriteString("\nFiles:\n")
+		for _, file := range d.Files {
+			sb.WriteString("  " + file + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// terms returns the tokens of every searchable field of the document
+func (d Document) terms() []string {
+	text := d.Subject + "\n" + d.Body + "\n" + strings.Join(d.Files, "\n")
+	return Tokenize(text)
+}
+
+// Result is a document matched by a search along with its BM25 score
+type Result struct {
+	Document
+	Score float64
+}
+
+// Index is a local, file backed search index of commits
+type Index struct {
+	// Head is the commit the index was last refreshed at
+	Head      string     `json:"head"`
+	Documents []Document `json:"documents"`
+}
+
+// Tokenize lowercases text and splits it into searchable terms,
+// dropping stop words and single characters. Paths and identifiers
+// are split on punctuation, so "internal/client.go" yields "internal", "client" and "go".
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if len(field) < 2 || stopWords[field] {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// Load reads an index from path. A missing file yields an empty index.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Index{}, nil
+		}
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	return &idx, nil
+}
+
+// Save writes the index to path, creating the parent directory if needed
+func (i *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	data, err := json.Marshal(i)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// Add inserts documents that are not indexed yet, keeping newer documents first.
+// It returns the number of documents added.
+func (i *Index) Add(docs []Document) int {
+	known := make(map[string]bool, len(i.Documents))
+	for _, doc := range i.Documents {
+		known[doc.Hash] = true
+	}
+
+	added := make([]Document, 0, len(docs))
+	for _, doc := range docs {
+		if known[doc.Hash] {
+			continue
+		}
+		known[doc.Hash] = true
+		added = append(added, doc)
+	}
+	i.Documents = append(added, i.Documents...)
+	return len(added)
+}
+
+// Search ranks the indexed documents against query with BM25 and returns
+// at most limit documents with a positive score, best match first.
+func (i *Index) Search(query string, limit int) []Result {
+	queryTerms := Tokenize(query)
+	if len(queryTerms) == 0 || len(i.Documents) == 0 {
+		return nil
+	}
+
+	// Term frequencies per document and document frequencies per term
+	termFreqs := make([]map[string]int, len(i.Documents))
+	docFreq := make(map[string]int)
+	totalLen := 0
+	for n, doc := range i.Documents {
+		tf := make(map[string]int)
+		terms := doc.terms()
+		for _, term := range terms {
+			tf[term]++
+		}
+		for term := range tf {
+			docFreq[term]++
+		}
+		termFreqs[n] = tf
+		totalLen += len(terms)
+	}
+	docCount := float64(len(i.Documents))
+	avgLen := float64(totalLen) / docCount
+
+	var results []Result
+	for n, doc := range i.Documents {
+		docLen := 0
+		for _, count := range termFreqs[n] {
+			docLen += count
+		}
+
+		score := 0.0
+		for _, term := range queryTerms {
+			tf := float64(termFreqs[n][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(docFreq[term])
+			idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+			score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(docLen)/avgLen))
+		}
+		if score > 0 {
+			results = append(results, Result{Document: doc, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(a, c int) bool {
+		return results[a].Score > results[c].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("When did we switch the Retry logic in internal/client/client.go?")
+	assert.Equal(t, []string{"switch", "retry", "logic", "internal", "client", "client", "go"}, tokens)
+	assert.Empty(t, Tokenize("a the of"))
+}
+
+func TestIndexAdd(t *testing.T) {
+	idx := &Index{Documents: []Document{{Hash: "old"}}}
+
+	added := idx.Add([]Document{{Hash: "new"}, {Hash: "old"}, {Hash: "new"}})
+	assert.Equal(t, 1, added)
+	require.Len(t, idx.Documents, 2)
+	assert.Equal(t, "new", idx.Documents[0].Hash)
+	assert.Equal(t, "old", idx.Documents[1].Hash)
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := &Index{Documents: []Document{
+		{Hash: "1", Subject: "docs: update readme"},
+		{Hash: "2", Subject: "feat: switch retry logic to exponential backoff", Body: "linear retry hammered the api"},
+		{Hash: "3", Subject: "fix: handle timeout", Files: []string{"internal/client/retry.go"}},
+		{Hash: "4", Subject: "chore: bump deps"},
+	}}
+
+	results := idx.Search("when did we switch the retry logic?", 10)
+	require.Len(t, results, 2)
+	assert.Equal(t, "2", results[0].Hash)
+	assert.Equal(t, "3", results[1].Hash)
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	assert.Len(t, idx.Search("retry", 1), 1)
+	assert.Empty(t, idx.Search("kubernetes", 10))
+	assert.Empty(t, idx.Search("the", 10))
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index", "repo.json")
+
+	idx, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, idx.Documents)
+
+	idx.Head = "abc"
+	idx.Add([]Document{{Hash: "abc", Subject: "init", Files: []string{"main.go"}}})
+	require.NoError(t, idx.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, idx, loaded)
+}
@@ -0,0 +1,332 @@
+// Package style learns the commit message conventions of a repository from its history.
+package style
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/belingud/go-gptcomet/internal/git"
+)
+
+// CacheFile is the name of the learned styles cache in the config directory
+const CacheFile = "style_cache.json"
+
+// CacheTTL is how long a learned style is reused before learning it again
+const CacheTTL = 24 * time.Hour
+
+// maxExampleBody is the longest body kept in an example, longer bodies are left out
+const maxExampleBody = 300
+
+var (
+	// conventionalRe matches "type(scope)!: summary" subjects
+	conventionalRe = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]+)\))?!?: (.+)$`)
+	// ticketRe matches ticket keys such as PROJ-123
+	ticketRe = regexp.MustCompile(`[A-Z][A-Z0-9]+-[0-9]+`)
+	// tagRe matches a leading bracketed tag such as "[net]"
+	tagRe = regexp.MustCompile(`^\[[^\]]*\]\s*`)
+)
+
+// Count is a value and how many commits used it
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Profile is the commit message style of a repository
+type Profile struct {
+	// Samples is the number of commits the style was learned from
+	Samples int `json:"samples"`
+	// Conventional is the share of subjects written as "type(scope): summary"
+	Conventional float64 `json:"conventional"`
+	// Types and Scopes are the conventional types and scopes, most used first
+	Types  []Count `json:"types,omitempty"`
+	Scopes []Count `json:"scopes,omitempty"`
+	// Casing is how summaries start: "lower", "capitalized" or "mixed"
+	Casing string `json:"casing"`
+	// AverageLength is the average subject length in characters
+	AverageLength int `json:"average_length"`
+	// BodyRatio is the share of commits with a body
+	BodyRatio float64 `json:"body_ratio"`
+	// Language is the language the messages are written in, guessed from their script,
+	// empty if it could not be told. Generated messages are written in output.lang.
+	Language string `json:"language"`
+	// Ticket is an example ticket key when most subjects reference one
+	Ticket string `json:"ticket,omitempty"`
+	// Examples are representative messages
+	Examples  []string  `json:"examples"`
+	LearnedAt time.Time `json:"learned_at"`
+}
+
+// skipped reports whether subject is not written by hand, e.g. a revert or a fixup
+func skipped(subject string) bool {
+	for _, prefix := range []string{"Revert ", "fixup! ", "squash! ", "amend! ", "Merge "} {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	return strings.TrimSpace(subject) == ""
+}
+
+// Learn derives the style of commits, newest first, keeping up to examples representative messages
+func Learn(commits []git.Commit, examples int) *Profile {
+	profile := &Profile{LearnedAt: time.Now()}
+	types := make(map[string]int)
+	scopes := make(map[string]int)
+	var conventional, lower, upper, bodies, length, tickets int
+	var text strings.Builder
+	var kept []git.Commit
+
+	for _, commit := range commits {
+		subject := strings.TrimSpace(commit.Subject)
+		if skipped(subject) {
+			continue
+		}
+		kept = append(kept, commit)
+		length += len([]rune(subject))
+		text.WriteString(subject + "\n" + commit.Body + "\n")
+		if commit.Body != "" {
+			bodies++
+		}
+		if ticketRe.MatchString(subject) {
+			tickets++
+			if profile.Ticket == "" {
+				profile.Ticket = ticketRe.FindString(subject)
+			}
+		}
+
+		summary := subject
+		if m := conventionalRe.FindStringSubmatch(subject); m != nil {
+			conventional++
+			types[strings.ToLower(m[1])]++
+			if m[2] != "" {
+				scopes[m[2]]++
+			}
+			summary = m[3]
+		}
+		// The casing is that of the first letter, after a ticket key or a bracketed tag
+		for _, r := range ticketRe.ReplaceAllString(tagRe.ReplaceAllString(summary, ""), "") {
+			if unicode.IsLower(r) {
+				lower++
+				break
+			} else if unicode.IsUpper(r) {
+				upper++
+				break
+			}
+		}
+	}
+
+	profile.Samples = len(kept)
+	if profile.Samples == 0 {
+		return profile
+	}
+	profile.Conventional = float64(conventional) / float64(profile.Samples)
+	profile.Types = sortCounts(types)
+	profile.Scopes = sortCounts(scopes)
+	profile.AverageLength = length / profile.Samples
+	profile.BodyRatio = float64(bodies) / float64(profile.Samples)
+	profile.Language = language(text.String())
+	if float64(tickets)/float64(profile.Samples) < 0.5 {
+		profile.Ticket = ""
+	}
+	switch {
+	case lower+upper == 0:
+		profile.Casing = "mixed"
+	case float64(lower) >= 0.8*float64(lower+upper):
+		profile.Casing = "lower"
+	case float64(upper) >= 0.8*float64(lower+upper):
+		profile.Casing = "capitalized"
+	default:
+		profile.Casing = "mixed"
+	}
+	profile.Examples = pickExamples(kept, profile, examples)
+	return profile
+}
+
+// sortCounts returns counts sorted by count, then name
+func sortCounts(counts map[string]int) []Count {
+	result := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, Count{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// language guesses the language of text from the script of its letters. Latin
+// script is shared by too many languages to tell them apart, it gives no language.
+func language(text string) string {
+	counts := map[string]int{}
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["Japanese"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["Korean"]++
+		case unicode.Is(unicode.Han, r):
+			counts["Chinese"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["Russian"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["Latin"]++
+		}
+	}
+	// Kana mixed with kanji is Japanese, and CJK messages often hold English identifiers
+	if counts["Japanese"] > 0 {
+		return "Japanese"
+	}
+	best, bestCount := "", 0
+	for _, name := range []string{"Chinese", "Korean", "Russian"} {
+		if counts[name] > bestCount {
+			best, bestCount = name, counts[name]
+		}
+	}
+	if bestCount*3 < counts["Latin"] {
+		return ""
+	}
+	return best
+}
+
+// pickExamples returns up to n messages matching the dominant form of profile: the
+// newest commit of each type first, then the newest remaining commits
+func pickExamples(commits []git.Commit, profile *Profile, n int) []string {
+	var candidates []git.Commit
+	for _, commit := range commits {
+		isConventional := conventionalRe.MatchString(commit.Subject)
+		if isConventional != (profile.Conventional >= 0.5) {
+			continue
+		}
+		// Leave out outliers, the examples set the expected length
+		if l := len([]rune(commit.Subject)); l > 2*profile.AverageLength || 2*l < profile.AverageLength {
+			continue
+		}
+		candidates = append(candidates, commit)
+	}
+
+	var picked []git.Commit
+	seenTypes := make(map[string]bool)
+	// A first pass takes one commit per type, the second fills with the newest commits
+	for pass := 0; pass < 2 && len(picked) < n; pass++ {
+		for _, commit := range candidates {
+			if len(picked) == n {
+				break
+			}
+			commitType := ""
+			if m := conventionalRe.FindStringSubmatch(commit.Subject); m != nil {
+				commitType = strings.ToLower(m[1])
+			}
+			if pass == 0 && seenTypes[commitType] {
+				continue
+			}
+			if pass == 1 && contains(picked, commit) {
+				continue
+			}
+			seenTypes[commitType] = true
+			picked = append(picked, commit)
+		}
+	}
+
+	examples := make([]string, 0, len(picked))
+	for _, commit := range picked {
+		message := strings.TrimSpace(commit.Subject)
+		if commit.Body != "" && len(commit.Body) <= maxExampleBody {
+			message += "\n\n" + commit.Body
+		}
+		examples = append(examples, message)
+	}
+	return examples
+}
+
+// contains reports whether commits holds commit
+func contains(commits []git.Commit, commit git.Commit) bool {
+	for _, c := range commits {
+		if c.Hash == commit.Hash && c.Subject == commit.Subject {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the names of up to n counts
+func names(counts []Count, n int) []string {
+	var result []string
+	for i, count := range counts {
+		if i == n {
+			break
+		}
+		result = append(result, count.Name)
+	}
+	return result
+}
+
+// Describe returns the conventions of the style, one per line, for prompts
+func (p *Profile) Describe() string {
+	if p == nil || p.Samples == 0 {
+		return ""
+	}
+	var lines []string
+	if p.Conventional >= 0.5 {
+		line := "Use the type(scope): summary form"
+		if types := names(p.Types, 6); len(types) > 0 {
+			line += ", the usual types are " + strings.Join(types, ", ")
+		}
+		lines = append(lines, line)
+		if scopes := names(p.Scopes, 8); len(scopes) > 0 {
+			lines = append(lines, "The usual scopes are "+strings.Join(scopes, ", "))
+		}
+	} else {
+		lines = append(lines, "Do not prefix the subject with a type")
+	}
+	switch p.Casing {
+	case "lower":
+		lines = append(lines, "Start the summary with a lowercase letter")
+	case "capitalized":
+		lines = append(lines, "Start the summary with a capital letter")
+	}
+	lines = append(lines, fmt.Sprintf("Keep the subject around %d characters", p.AverageLength))
+	if p.Ticket != "" {
+		lines = append(lines, "Reference the ticket key in the subject, e.g. "+p.Ticket)
+	}
+	if p.BodyRatio < 0.2 {
+		lines = append(lines, "Most commits have no body")
+	}
+	return "- " + strings.Join(lines, "\n- ")
+}
+
+// LoadCache reads the learned styles keyed by repository
+func LoadCache(path string) (map[string]*Profile, error) {
+	cache := make(map[string]*Profile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("failed to read style cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse style cache: %w", err)
+	}
+	return cache, nil
+}
+
+// SaveCache writes the learned styles
+func SaveCache(path string, cache map[string]*Profile) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal style cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write style cache: %w", err)
+	}
+	return nil
+}
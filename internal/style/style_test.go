@@ -0,0 +1,87 @@
+package style
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLearn(t *testing.T) {
+	commits := []git.Commit{
+		{Hash: "1", Subject: "fix(config): handle empty file_ignore"},
+		{Hash: "2", Subject: "feat(cmd): add style command", Body: "- learn from git log"},
+		{Hash: "3", Subject: "fix(git): quote paths with spaces"},
+		{Hash: "4", Subject: "Revert \"feat: add x\""},
+		{Hash: "5", Subject: "docs: describe prompt variables"},
+		{Hash: "6", Subject: "fix(config): keep list order when merging"},
+		{Hash: "7", Subject: "Update readme"},
+	}
+
+	profile := Learn(commits, 3)
+	assert.Equal(t, 6, profile.Samples)
+	assert.InDelta(t, 5.0/6, profile.Conventional, 0.001)
+	assert.Equal(t, []Count{{"fix", 3}, {"docs", 1}, {"feat", 1}}, profile.Types)
+	assert.Equal(t, Count{"config", 2}, profile.Scopes[0])
+	assert.Equal(t, "lower", profile.Casing)
+	// Latin script does not tell the language
+	assert.Empty(t, profile.Language)
+	assert.Empty(t, profile.Ticket)
+	// One example per type first, newest first
+	assert.Equal(t, []string{
+		"fix(config): handle empty file_ignore",
+		"feat(cmd): add style command\n\n- learn from git log",
+		"docs: describe prompt variables",
+	}, profile.Examples)
+
+	description := profile.Describe()
+	assert.Contains(t, description, "the usual types are fix, docs, feat")
+	assert.Contains(t, description, "The usual scopes are config, cmd, git")
+	assert.Contains(t, description, "Start the summary with a lowercase letter")
+	assert.NotContains(t, description, "Write in")
+}
+
+func TestLearnTicketsAndLanguage(t *testing.T) {
+	profile := Learn([]git.Commit{
+		{Subject: "PROJ-12 修复配置文件为空时崩溃"},
+		{Subject: "PROJ-13 添加提交风格学习"},
+		{Subject: "更新文档"},
+	}, 2)
+	assert.Equal(t, "PROJ-12", profile.Ticket)
+	assert.Equal(t, "Chinese", profile.Language)
+	// output.lang decides the language of generated messages
+	assert.NotContains(t, profile.Describe(), "Chinese")
+	assert.Equal(t, "mixed", profile.Casing)
+	assert.Contains(t, profile.Describe(), "Do not prefix the subject with a type")
+	assert.Contains(t, profile.Describe(), "e.g. PROJ-12")
+
+	assert.Empty(t, Learn(nil, 3).Describe())
+}
+
+func TestLanguage(t *testing.T) {
+	assert.Equal(t, "Chinese", language("修复 config 加载"))
+	assert.Equal(t, "Japanese", language("設定を修正"))
+	assert.Equal(t, "Russian", language("исправить конфиг"))
+	// French and English share the Latin script
+	assert.Empty(t, language("corrige le chargement de la configuration"))
+	assert.Empty(t, language("fix config loading"))
+	assert.Empty(t, language(""))
+}
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CacheFile)
+
+	cache, err := LoadCache(path)
+	require.NoError(t, err)
+	assert.Empty(t, cache)
+
+	cache["/repo"] = Learn([]git.Commit{{Subject: "fix: a"}}, 1)
+	require.NoError(t, SaveCache(path, cache))
+
+	loaded, err := LoadCache(path)
+	require.NoError(t, err)
+	require.Contains(t, loaded, "/repo")
+	assert.Equal(t, []string{"fix: a"}, loaded["/repo"].Examples)
+}
@@ -214,6 +214,17 @@ func NewConfigInput(configs map[string]config.ConfigRequirement) *ConfigInput {
 	}
 }
 
+// SetSuggestions offers suggestions for the input of key, accepted with Tab
+func (m *ConfigInput) SetSuggestions(key string, suggestions []string) {
+	for i, k := range m.configKeys {
+		if k == key {
+			m.inputs[i].ShowSuggestions = len(suggestions) > 0
+			m.inputs[i].SetSuggestions(suggestions)
+			return
+		}
+	}
+}
+
 func (m *ConfigInput) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -299,6 +310,9 @@ func (m *ConfigInput) View() string {
 	s.WriteString(":\n")
 	s.WriteString(m.inputs[m.currentKey].View())
 	s.WriteString("\n\n")
+	if m.inputs[m.currentKey].ShowSuggestions {
+		s.WriteString("Press Tab to accept a suggestion, Up/Down to cycle through them\n")
+	}
 
 	// show progress
 	s.WriteString(fmt.Sprintf("(%d/%d) Press Enter to continue, Esc to quit", m.currentKey+1, len(m.inputs)))
@@ -341,3 +341,18 @@ func TestItemDelegate_Interface(t *testing.T) {
 		t.Errorf("Expected spacing 0, got %d", d.Spacing())
 	}
 }
+
+func TestConfigInput_SetSuggestions(t *testing.T) {
+	input := NewConfigInput(map[string]config.ConfigRequirement{
+		"api_key": {},
+		"model":   {DefaultValue: "gpt-4o"},
+	})
+
+	input.SetSuggestions("model", []string{"gpt-4o", "gpt-4o-mini"})
+	assert.False(t, input.inputs[0].ShowSuggestions)
+	assert.True(t, input.inputs[1].ShowSuggestions)
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, input.inputs[1].AvailableSuggestions())
+
+	input.SetSuggestions("model", nil)
+	assert.False(t, input.inputs[1].ShowSuggestions)
+}
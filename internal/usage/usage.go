@@ -0,0 +1,293 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/belingud/go-gptcomet/internal/debug"
+)
+
+// LedgerFile is the name of the ledger file in the config directory
+const LedgerFile = "usage.jsonl"
+
+// Record is a single request entry of the usage ledger
+type Record struct {
+	Time             time.Time `json:"time"`
+	Command          string    `json:"command"`
+	Repo             string    `json:"repo,omitempty"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	DurationMs       int64     `json:"duration_ms"`
+	// Cost is the estimated cost in USD at the time of the request, if the model price was known
+	Cost float64 `json:"cost,omitempty"`
+}
+
+// Ledger is an append-only JSONL file of usage records
+type Ledger struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewLedger creates a ledger stored at path
+func NewLedger(path string) *Ledger {
+	return &Ledger{Path: path}
+}
+
+// Append adds record to the ledger
+func (l *Ledger) Append(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage record: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	return nil
+}
+
+// Read returns the records made at or after since, oldest first.
+// Malformed lines are skipped.
+func (l *Ledger) Read(since time.Time) ([]Record, error) {
+	f, err := os.Open(l.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			debug.Printf("Skipping malformed ledger line: %v", err)
+			continue
+		}
+		if record.Time.Before(since) {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+	return records, nil
+}
+
+// Spent returns the tokens and estimated cost recorded for provider since the given time
+func (l *Ledger) Spent(provider string, since time.Time) (int, float64, error) {
+	records, err := l.Read(since)
+	if err != nil {
+		return 0, 0, err
+	}
+	var (
+		tokens int
+		cost   float64
+	)
+	for _, r := range records {
+		if r.Provider == provider {
+			tokens += r.TotalTokens
+			cost += r.Cost
+		}
+	}
+	return tokens, cost, nil
+}
+
+var (
+	defaultLedger *Ledger
+	command       string
+	repo          string
+)
+
+// Enable records the usage of every request made in this process to the ledger at path,
+// attributed to the given command and the repository of the working directory
+func Enable(path string, cmd string) {
+	defaultLedger = NewLedger(path)
+	command = cmd
+	repo = currentRepo()
+}
+
+// Disable stops recording usage
+func Disable() {
+	defaultLedger = nil
+}
+
+// Add records a request in the enabled ledger, filling in the command and repository.
+// It does nothing if recording is not enabled.
+func Add(record Record) {
+	if defaultLedger == nil {
+		return
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	record.Command = command
+	record.Repo = repo
+	if err := defaultLedger.Append(record); err != nil {
+		debug.Printf("Failed to record usage: %v", err)
+	}
+}
+
+// Recording reports whether Add records requests to the ledger at path
+func Recording(path string) bool {
+	return defaultLedger != nil && defaultLedger.Path == path
+}
+
+// currentRepo returns the name of the repository containing the working directory,
+// found by looking for a .git or .svn entry in the parent directories
+func currentRepo() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, marker := range []string{".git", ".svn"} {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return filepath.Base(dir)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// DefaultPrices is the built-in price table, overridable with usage.prices.<model>
+var DefaultPrices = map[string]Price{
+	"gpt-4o":            {Prompt: 2.5, Completion: 10},
+	"gpt-4o-mini":       {Prompt: 0.15, Completion: 0.6},
+	"o1":                {Prompt: 15, Completion: 60},
+	"o1-mini":           {Prompt: 3, Completion: 12},
+	"claude-3-5-sonnet": {Prompt: 3, Completion: 15},
+	"claude-3-5-haiku":  {Prompt: 0.8, Completion: 4},
+	"claude-3-opus":     {Prompt: 15, Completion: 75},
+	"gemini-1.5-pro":    {Prompt: 1.25, Completion: 5},
+	"gemini-1.5-flash":  {Prompt: 0.075, Completion: 0.3},
+	"deepseek-chat":     {Prompt: 0.27, Completion: 1.1},
+	"mistral-large":     {Prompt: 2, Completion: 6},
+}
+
+// LookupPrice returns the price of model, matching the longest price table key
+// the model name starts with, so dated model versions use the base model price
+func LookupPrice(prices map[string]Price, model string) (Price, bool) {
+	if price, ok := prices[model]; ok {
+		return price, true
+	}
+	var (
+		best  string
+		found Price
+	)
+	for name, price := range prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+			found = price
+		}
+	}
+	return found, best != ""
+}
+
+// Cost returns the estimated cost of record in USD
+func (p Price) Cost(record Record) float64 {
+	return (float64(record.PromptTokens)*p.Prompt + float64(record.CompletionTokens)*p.Completion) / 1e6
+}
+
+// Summary aggregates the records of a group
+type Summary struct {
+	Key              string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             float64
+	// Unpriced counts the requests whose model is not in the price table
+	Unpriced int
+}
+
+// Summarize groups records by "model", "repo" or "day" and estimates their cost.
+// Days are sorted chronologically, other groups by descending cost.
+func Summarize(records []Record, by string, prices map[string]Price) ([]Summary, error) {
+	var keyFunc func(Record) string
+	switch by {
+	case "model":
+		keyFunc = func(r Record) string { return r.Provider + "/" + r.Model }
+	case "repo":
+		keyFunc = func(r Record) string {
+			if r.Repo == "" {
+				return "(none)"
+			}
+			return r.Repo
+		}
+	case "day":
+		keyFunc = func(r Record) string { return r.Time.Local().Format("2006-01-02") }
+	default:
+		return nil, fmt.Errorf("unsupported grouping %q, use model, repo or day", by)
+	}
+
+	groups := make(map[string]*Summary)
+	for _, r := range records {
+		key := keyFunc(r)
+		s, ok := groups[key]
+		if !ok {
+			s = &Summary{Key: key}
+			groups[key] = s
+		}
+		s.Requests++
+		s.PromptTokens += r.PromptTokens
+		s.CompletionTokens += r.CompletionTokens
+		s.TotalTokens += r.TotalTokens
+		if price, ok := LookupPrice(prices, r.Model); ok {
+			s.Cost += price.Cost(r)
+		} else {
+			s.Unpriced++
+		}
+	}
+
+	summaries := make([]Summary, 0, len(groups))
+	for _, s := range groups {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if by != "day" && summaries[i].Cost != summaries[j].Cost {
+			return summaries[i].Cost > summaries[j].Cost
+		}
+		return summaries[i].Key < summaries[j].Key
+	})
+	return summaries, nil
+}
@@ -0,0 +1,105 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", LedgerFile)
+	ledger := NewLedger(path)
+
+	records, err := ledger.Read(time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	now := time.Now()
+	require.NoError(t, ledger.Append(Record{Time: now.Add(-48 * time.Hour), Provider: "openai", Model: "gpt-4o", TotalTokens: 10}))
+	require.NoError(t, ledger.Append(Record{Time: now, Provider: "openai", Model: "gpt-4o-mini", TotalTokens: 20}))
+
+	// Malformed lines are skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("not json\n")
+	require.NoError(t, err)
+	f.Close()
+
+	records, err = ledger.Read(time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = ledger.Read(now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "gpt-4o-mini", records[0].Model)
+}
+
+func TestAdd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LedgerFile)
+
+	Add(Record{Model: "ignored"})
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "nothing is recorded before Enable")
+
+	Enable(path, "commit")
+	defer Disable()
+	Add(Record{Provider: "openai", Model: "gpt-4o", PromptTokens: 3})
+
+	records, err := NewLedger(path).Read(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "commit", records[0].Command)
+	assert.False(t, records[0].Time.IsZero())
+}
+
+func TestLookupPrice(t *testing.T) {
+	price, ok := LookupPrice(DefaultPrices, "gpt-4o-mini-2024-07-18")
+	require.True(t, ok)
+	assert.Equal(t, DefaultPrices["gpt-4o-mini"], price)
+
+	price, ok = LookupPrice(DefaultPrices, "gpt-4o-2024-08-06")
+	require.True(t, ok)
+	assert.Equal(t, DefaultPrices["gpt-4o"], price)
+
+	_, ok = LookupPrice(DefaultPrices, "llama3")
+	assert.False(t, ok)
+}
+
+func TestSummarize(t *testing.T) {
+	day1 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	records := []Record{
+		{Time: day1, Repo: "api", Provider: "openai", Model: "gpt-4o", PromptTokens: 1000000, CompletionTokens: 100000, TotalTokens: 1100000},
+		{Time: day2, Repo: "api", Provider: "openai", Model: "gpt-4o-mini", PromptTokens: 1000000, TotalTokens: 1000000},
+		{Time: day2, Provider: "ollama", Model: "llama3", PromptTokens: 50, TotalTokens: 50},
+	}
+
+	summaries, err := Summarize(records, "model", DefaultPrices)
+	require.NoError(t, err)
+	require.Len(t, summaries, 3)
+	assert.Equal(t, "openai/gpt-4o", summaries[0].Key)
+	assert.InDelta(t, 3.5, summaries[0].Cost, 1e-9)
+	assert.Equal(t, "ollama/llama3", summaries[2].Key)
+	assert.Equal(t, 1, summaries[2].Unpriced)
+
+	summaries, err = Summarize(records, "repo", DefaultPrices)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "api", summaries[0].Key)
+	assert.Equal(t, 2, summaries[0].Requests)
+	assert.Equal(t, "(none)", summaries[1].Key)
+
+	summaries, err = Summarize(records, "day", DefaultPrices)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "2024-05-01", summaries[0].Key)
+	assert.Equal(t, "2024-05-02", summaries[1].Key)
+
+	_, err = Summarize(records, "week", DefaultPrices)
+	assert.Error(t, err)
+}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/belingud/go-gptcomet/cmd"
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/config"
 	"github.com/belingud/go-gptcomet/internal/debug"
+	"github.com/belingud/go-gptcomet/internal/llm"
+	"github.com/belingud/go-gptcomet/internal/usage"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +21,7 @@ func main() {
 	var (
 		debugEnabled bool
 		configPath   string
+		overrides    []string
 	)
 
 	var rootCmd = &cobra.Command{
@@ -22,22 +29,68 @@ func main() {
 		Short:        "GPTComet - AI-powered Git commit message generator",
 		Version:      version,
 		SilenceUsage: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			debug.Enable(debugEnabled)
 			debug.Printf("Debug mode enabled")
 			if configPath != "" {
 				debug.Printf("Using config file: %s", configPath)
 			}
+
+			// Record token usage of every request in the ledger next to the config file
+			if path, err := config.ResolvePath(configPath); err == nil {
+				command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+				usage.Enable(filepath.Join(filepath.Dir(path), usage.LedgerFile), command)
+			}
+
+			// Command line overrides take precedence over every config file
+			values := make(map[string]interface{}, len(overrides))
+			for _, override := range overrides {
+				key, value, err := config.ParseOverride(override)
+				if err != nil {
+					return err
+				}
+				values[key] = value
+			}
+			config.SetOverrides(values)
+			return nil
 		},
 	}
 
 	// Add persistent flags to root command
 	rootCmd.PersistentFlags().BoolVarP(&debugEnabled, "debug", "d", false, "Enable debug mode")
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Config file path")
+	rootCmd.PersistentFlags().StringArrayVar(&overrides, "set", nil, "Override a config value for this run, e.g. --set output.lang=fr")
 
 	rootCmd.AddCommand(cmd.NewProviderCmd())
+	rootCmd.AddCommand(cmd.NewNewProviderCmd())
 	rootCmd.AddCommand(cmd.NewCommitCmd())
 	rootCmd.AddCommand(cmd.NewConfigCmd())
+	rootCmd.AddCommand(cmd.NewWhyCmd())
+	rootCmd.AddCommand(cmd.NewStandupCmd())
+	rootCmd.AddCommand(cmd.NewAskCmd())
+	rootCmd.AddCommand(cmd.NewResolveCmd())
+	rootCmd.AddCommand(cmd.NewBranchCmd())
+	rootCmd.AddCommand(cmd.NewStashCmd())
+	rootCmd.AddCommand(cmd.NewDoctorCmd())
+	rootCmd.AddCommand(cmd.NewModelsCmd())
+	rootCmd.AddCommand(cmd.NewUsageCmd())
+	rootCmd.AddCommand(cmd.NewHistoryCmd())
+	rootCmd.AddCommand(cmd.NewEvalCmd())
+	rootCmd.AddCommand(cmd.NewBenchCmd())
+	rootCmd.AddCommand(cmd.NewServeCmd())
+	rootCmd.AddCommand(cmd.NewRPCCmd())
+	rootCmd.AddCommand(cmd.NewStyleCmd())
+
+	// Run the root hooks, which apply the --set overrides, before those of subcommands
+	cobra.EnableTraverseRunHooks = true
+
+	// Ask before sending requests over a budget limit
+	client.SetLimitConfirm(cmd.ConfirmLimit)
+	// Show the discovered provider and the token usage of each request
+	config.SetOutput(os.Stdout)
+	llm.SetUsageOutput(os.Stdout)
+	// Show when a request falls back to another provider
+	client.SetOutput(os.Stderr)
 
 	if err := rootCmd.Execute(); err != nil {
 		// fmt.Fprintln(os.Stderr, err)
@@ -11,19 +11,14 @@ Guidelines:
 - focus on the most significant changes.
 - sometimes you need to judge the effect based on the type of files that have been modified.
 
-use one of the following labels for the title:
-
-- build: changes that affect the build system or external dependencies (example scopes: gulp, broccoli, npm)
-- chore: updating libraries, copyrights or other setting, includes updating dependencies.
-- ci: changes to our CI configuration files and scripts (example scopes: Travis, Circle, gitHub Actions)
-- docs: non-code changes, such as fixing typos or adding new documentation
-- feat: a commit of the type feat introduces a new feature to the codebase
-- fix: a commit of the type fix patches a bug in your codebase
-- perf: a code change that improves performance
-- refactor: a code change that neither fixes a bug nor adds a feature
-- style: changes that do not affect the meaning of the code (white-space, formatting, missing semi-colons, etc)
-- test: adding missing tests or correcting existing tests
+{{ if eq .output.style "plain" }}write the title as a plain imperative sentence, without a label or an emoji.
+{{ else if eq .output.style "gitmoji" }}start the title with the gitmoji of one of the following labels, followed by the label, e.g. ":sparkles: feat: add login":
 
+{{ range .commit_types }}- {{ if .Emoji }}{{ .Emoji }} {{ end }}{{ .Name }}{{ if .Description }}: {{ .Description }}{{ end }}
+{{ end }}{{ else }}use one of the following labels for the title:
+
+{{ range .commit_types }}- {{ .Name }}{{ if .Description }}: {{ .Description }}{{ end }}
+{{ end }}{{ end }}
 The commit message template is <title>: <summary>. Your answer should only include a single commit message less than 70 characters, no other text or ` + "`" + `.
 If your answer includes details about the commit, please list each item on a new line.
 
@@ -48,7 +43,21 @@ Examples:
 test: update import of stylize test
 fix: Fix password hashing vulnerability
 
-Generate commit message by below git diff:
+{{ if .style }}Follow the commit conventions of this repository:
+{{ .style }}
+{{ if .style_examples }}
+Recent commit messages of this repository, as examples of the style:
+{{ range .style_examples }}
+{{ . }}
+{{ end }}{{ end }}
+{{ end }}{{ if .related_history }}Recent commits touching the staged files, reuse their scope names and terminology:
+{{ .related_history }}
+
+{{ end }}{{ if .scopes }}The scope of this commit is derived from the paths of the staged files, use ({{ join .scopes "," }}) as the scope.
+
+{{ end }}{{ if .hint }}Context from the author: {{ .hint }}
+
+{{ end }}Generate commit message by below git diff:
 {{ placeholder }}
 
 Commit Message:`,
@@ -61,19 +70,14 @@ Guidelines:
 - focus on the most significant changes.
 - sometimes you need to judge the effect based on the type of files that have been modified.
 
-use one of the following labels for the title:
+{{ if eq .output.style "plain" }}write the title as a plain imperative sentence, without a label or an emoji.
+{{ else if eq .output.style "gitmoji" }}start the title with the gitmoji of one of the following labels, followed by the label, e.g. ":sparkles: feat: add login":
 
-- build: changes that affect the build system or external dependencies (example scopes: gulp, broccoli, npm)
-- chore: updating libraries, copyrights or other setting, includes updating dependencies.
-- ci: changes to our CI configuration files and scripts (example scopes: Travis, Circle, gitHub Actions)
-- docs: non-code changes, such as fixing typos or adding new documentation
-- feat: a commit of the type feat introduces a new feature to the codebase
-- fix: a commit of the type fix patches a bug in your codebase
-- perf: a code change that improves performance
-- refactor: a code change that neither fixes a bug nor adds a feature
-- style: changes that do not affect the meaning of the code (white-space, formatting, missing semi-colons, etc)
-- test: adding missing tests or correcting existing tests
+{{ range .commit_types }}- {{ if .Emoji }}{{ .Emoji }} {{ end }}{{ .Name }}{{ if .Description }}: {{ .Description }}{{ end }}
+{{ end }}{{ else }}use one of the following labels for the title:
 
+{{ range .commit_types }}- {{ .Name }}{{ if .Description }}: {{ .Description }}{{ end }}
+{{ end }}{{ end }}
 The commit message template is {{ output.rich_template }}. Your answer should only include commit message, no other text or ` + "`" + `.
 If your answer includes details about the commit, please list each item on a new line.
 
@@ -100,7 +104,21 @@ feat: support generating rich commit message
 - implement rich commit message generate function
 - delete unused functions in message generater
 
-Generate commit message by below git diff:
+{{ if .style }}Follow the commit conventions of this repository:
+{{ .style }}
+{{ if .style_examples }}
+Recent commit messages of this repository, as examples of the style:
+{{ range .style_examples }}
+{{ . }}
+{{ end }}{{ end }}
+{{ end }}{{ if .related_history }}Recent commits touching the staged files, reuse their scope names and terminology:
+{{ .related_history }}
+
+{{ end }}{{ if .scopes }}The scope of this commit is derived from the paths of the staged files, use ({{ join .scopes "," }}) as the scope.
+
+{{ end }}{{ if .hint }}Context from the author: {{ .hint }}
+
+{{ end }}Generate commit message by below git diff:
 {{ placeholder }}
 
 Commit Message:`,
@@ -115,4 +133,144 @@ GIT COMMIT MESSAGE:
 
 Remember translate all given git commit message and give me only the translation.
 THE TRANSLATION:`,
+	"why": `You are an expert software engineer helping a teammate during code review.
+Task: Explain why lines {{ lines }} of {{ file }} look the way they do today, based on the commits that shaped them.
+
+Guidelines:
+- tell the story in chronological order, from the oldest commit to the newest.
+- cite the short commit hash in square brackets, like [1a2b3c4], for every claim you make.
+- focus on the intent and trade-offs described in the commit messages, not on restating the diff.
+- if the history does not explain a change, say so instead of guessing.
+- answer in {{ output.lang }}.
+
+Commit history of these lines, newest first, as produced by git log -L:
+{{ placeholder }}
+
+Explanation:`,
+	"standup": `You are an expert software engineer preparing a status update for a daily standup.
+Task: Summarize the commits below, made {{ since }}, into a short report that can be pasted as is.
+
+Guidelines:
+- use a bulleted list grouped by {{ group }}, with the group name as a heading.
+- merge related commits into one bullet and describe the outcome, not the individual commits.
+- keep each bullet to one line, in past tense.
+- skip trivial commits such as typo fixes and merges unless they are the only work done.
+- answer in {{ output.lang }}, with no other text.
+
+Commits:
+{{ placeholder }}
+
+Standup summary:`,
+	"ask": `You are an expert software engineer who knows the history of this repository.
+Task: Answer the question below using only the commits provided as context.
+
+Guidelines:
+- cite the short commit hash in square brackets, like [1a2b3c4], for every fact you use.
+- mention dates when the question asks when something happened.
+- if the commits do not contain the answer, say so instead of guessing.
+- answer in {{ output.lang }}.
+
+Question:
+{{ question }}
+
+Relevant commits, best match first:
+{{ placeholder }}
+
+Answer:`,
+	"resolve": `You are an expert software engineer resolving a merge conflict in {{ file }}.
+Task: Combine both sides of the conflict below into a single correct version of the code.
+
+Guidelines:
+- keep the intent of both sides; when they truly contradict, prefer the change that the commit messages describe as newer or more deliberate.
+- use the base version, when given, to tell which side changed what.
+- keep the surrounding style, indentation and line endings.
+- answer with the resolved code only, without conflict markers, explanations or ` + "`" + `.
+
+Commits on our side ({{ ours_label }}):
+{{ ours_commits }}
+
+Commits on their side ({{ theirs_label }}):
+{{ theirs_commits }}
+
+Our version:
+{{ ours }}
+Base version:
+{{ base }}
+Their version:
+{{ theirs }}
+Resolved code:`,
+	"branch": `You are an expert software engineer naming a git branch.
+Task: Classify the work described below and summarize it in a few words.
+
+Guidelines:
+- use one of the following types: {{ types }}.
+- the summary must be 2 to 6 words, lowercase, in English, without punctuation or ticket keys.
+- answer with a single line in the format <type>: <summary>, no other text or ` + "`" + `.
+
+Examples:
+feat: add oauth login
+fix: handle empty config file
+
+Work to name:
+{{ placeholder }}
+
+Branch:`,
+	"stash": `You are an expert software engineer labelling a git stash so it can be recognized weeks later.
+Task: Summarize the work in progress shown in the git diff below in a single line.
+
+Guidelines:
+- use the imperative mood, like a commit title, without a type label.
+- mention the feature or component being worked on.
+- keep it under 60 characters.
+- answer with the summary only, no other text or ` + "`" + `.
+
+Git diff:
+{{ placeholder }}
+
+Summary:`,
+	"eval_judge": `You are an expert software engineer reviewing commit messages.
+Task: Rate how well the candidate commit message describes the git diff below, using the reference message written by the author as a guide.
+
+Guidelines:
+- a good message names the intent of the change, not just the files touched.
+- wording may differ from the reference as long as the meaning matches.
+- penalize wrong commit types, invented changes and overly long titles.
+- answer with a single integer from 1 (useless) to 10 (as good as the reference), no other text.
+
+Git diff:
+{{ placeholder }}
+
+Reference message:
+{{ reference }}
+
+Candidate message:
+{{ candidate }}
+
+Score:`,
+	"review": `You are an expert software engineer doing a code review.
+Task: Review the git diff below before it is committed.
+
+Guidelines:
+- point out bugs, missing error handling, security issues and unclear code, with the file and line they concern.
+- suggest a concrete fix for every issue.
+- skip style nitpicks a formatter would fix.
+- if the change looks good, say so in one sentence.
+- answer in {{ output.lang }}.
+
+Git diff:
+{{ placeholder }}
+
+Review:`,
+	"explain": `You are an expert software engineer explaining code to a teammate.
+Task: Explain what the following {{ language }} code does and why it is written this way.
+
+Guidelines:
+- start with a one sentence overview, then walk through the important parts.
+- mention pitfalls or non-obvious behavior.
+- answer in {{ output.lang }}.
+
+Code:
+{{ placeholder }}
+
+Explanation:`,
 }
@@ -18,9 +18,7 @@ func TestPromptDefaults(t *testing.T) {
 				"software engineer",
 				"commit message",
 				"Guidelines",
-				"build:",
-				"feat:",
-				"fix:",
+				"{{ range .commit_types }}",
 			},
 		},
 		{
@@ -30,9 +28,7 @@ func TestPromptDefaults(t *testing.T) {
 				"software engineer",
 				"commit message",
 				"Guidelines",
-				"build:",
-				"feat:",
-				"fix:",
+				"{{ range .commit_types }}",
 				"{{ output.rich_template }}",
 			},
 		},
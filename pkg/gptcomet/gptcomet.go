@@ -0,0 +1,455 @@
+// Package gptcomet generates commit messages from diffs with LLM providers.
+//
+// It is the importable API of the gptcomet command: build a Client with options,
+// then generate commit messages and translations. The package never prints to
+// stdout or reads from stdin.
+//
+//	c, err := gptcomet.New(gptcomet.WithConfigFile(""))
+//	if err != nil {
+//		return err
+//	}
+//	message, err := c.GenerateCommitMessage(ctx, diff)
+package gptcomet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/belingud/go-gptcomet/internal/client"
+	"github.com/belingud/go-gptcomet/internal/commitmsg"
+	"github.com/belingud/go-gptcomet/internal/committype"
+	"github.com/belingud/go-gptcomet/internal/config"
+	"github.com/belingud/go-gptcomet/internal/git"
+	"github.com/belingud/go-gptcomet/internal/llm"
+	"github.com/belingud/go-gptcomet/internal/prompt"
+	"github.com/belingud/go-gptcomet/pkg/config/defaults"
+	"github.com/belingud/go-gptcomet/pkg/types"
+)
+
+// ClientConfig is the configuration of a provider
+type ClientConfig = types.ClientConfig
+
+// Message is a message of a conversation
+type Message = types.Message
+
+// Usage is the token usage reported by a provider
+type Usage = types.Usage
+
+// Response is the answer of a provider
+type Response = types.CompletionResponse
+
+// LLM is the interface implemented by providers, see RegisterProvider
+type LLM = llm.LLM
+
+// BaseLLM implements most of LLM for OpenAI compatible APIs. Custom providers can embed it,
+// add Name and a MakeRequest calling BaseLLM.MakeRequest with themselves as provider,
+// and override the methods that differ.
+type BaseLLM = llm.BaseLLM
+
+// ProviderConstructor creates a provider from its configuration
+type ProviderConstructor = llm.ProviderConstructor
+
+// NewBaseLLM creates a BaseLLM, filling the defaults of config
+func NewBaseLLM(config *ClientConfig) *BaseLLM {
+	return llm.NewBaseLLM(config)
+}
+
+// RegisterProvider registers a custom provider. A ClientConfig whose Provider is name
+// is then answered by the LLM built by constructor. Built-in providers cannot be replaced.
+func RegisterProvider(name string, constructor ProviderConstructor) error {
+	return llm.RegisterProvider(name, constructor)
+}
+
+// VCS reads the staged changes of a repository
+type VCS interface {
+	StagedDiff(repoPath string) (string, error)
+}
+
+// stagedDiffer is implemented by the git and svn VCS
+type stagedDiffer interface {
+	GetStagedDiffIgnoring(repoPath string, ignorePatterns []string) (string, error)
+}
+
+// vcs adapts a VCS of the git package to VCS
+type vcs struct {
+	differ stagedDiffer
+	ignore []string
+}
+
+// StagedDiff returns the diff of the staged changes, without the ignored files
+func (v *vcs) StagedDiff(repoPath string) (string, error) {
+	return v.differ.GetStagedDiffIgnoring(repoPath, v.ignore)
+}
+
+// Git returns a VCS reading the staged changes with git, skipping the files matching
+// the ignore patterns
+func Git(ignore ...string) VCS {
+	return &vcs{differ: &git.GitVCS{}, ignore: ignore}
+}
+
+// SVN returns a VCS reading the changes with svn, skipping the files matching
+// the ignore patterns
+func SVN(ignore ...string) VCS {
+	return &vcs{differ: &git.SVNVCS{}, ignore: ignore}
+}
+
+// Client generates commit messages with a provider
+type Client struct {
+	config            *ClientConfig
+	cfgManager        *config.Manager
+	provider          string
+	profile           string
+	prompt            string
+	rich              bool
+	richTemplate      string
+	commitTypes       []committype.Type
+	outputStyle       config.OutputStyleConfig
+	translationPrompt string
+	lang              string
+	vcs               VCS
+	httpClient        *http.Client
+	notify            func(message string)
+	// configFile is loaded by New when withConfig is set
+	configFile string
+	withConfig bool
+	repoPath   string
+
+	client *client.Client
+}
+
+// Option configures a Client
+type Option func(c *Client) error
+
+// WithClientConfig answers requests with the provider described by config
+func WithClientConfig(config *ClientConfig) Option {
+	return func(c *Client) error {
+		if config == nil {
+			return fmt.Errorf("client config cannot be nil")
+		}
+		c.config = config
+		return nil
+	}
+}
+
+// WithConfigFile reads the provider, prompts, output language and ignored files from
+// a gptcomet config file. An empty path uses the default config file. The file must
+// exist, it is never created.
+func WithConfigFile(path string) Option {
+	return func(c *Client) error {
+		c.configFile = path
+		c.withConfig = true
+		return nil
+	}
+}
+
+// WithRepo also reads the .gptcomet.yaml config of the repository holding path, over
+// the config file loaded with WithConfigFile
+func WithRepo(path string) Option {
+	return func(c *Client) error {
+		if path == "" {
+			return fmt.Errorf("repository path cannot be empty")
+		}
+		c.repoPath = path
+		return nil
+	}
+}
+
+// WithNotify passes notices, such as a request falling back to another provider over
+// a budget limit or a generated message with an unknown commit type, to notify. They
+// are discarded by default.
+func WithNotify(notify func(message string)) Option {
+	return func(c *Client) error {
+		c.notify = notify
+		return nil
+	}
+}
+
+// WithProvider selects a provider of the config file instead of the active one
+func WithProvider(name string) Option {
+	return func(c *Client) error {
+		c.provider = name
+		return nil
+	}
+}
+
+// WithProfile uses the prompts and model parameters of the prompt profile stored under
+// prompt.profiles.<name> in the config file, prompt.profile by default
+func WithProfile(name string) Option {
+	return func(c *Client) error {
+		c.profile = name
+		return nil
+	}
+}
+
+// WithPrompt sets the commit message prompt, a template where {{ diff }} is the diff and
+// {{ output.lang }} and {{ output.rich_template }} are the output language and rich format
+func WithPrompt(prompt string) Option {
+	return func(c *Client) error {
+		c.prompt = prompt
+		return nil
+	}
+}
+
+// WithRichPrompt uses the rich commit message prompt, with a title, summary and details,
+// unless a prompt is set with WithPrompt
+func WithRichPrompt() Option {
+	return func(c *Client) error {
+		c.rich = true
+		return nil
+	}
+}
+
+// WithTranslationPrompt sets the translation prompt, a template where {{ message }} is
+// the message and {{ output.lang }} the language
+func WithTranslationPrompt(prompt string) Option {
+	return func(c *Client) error {
+		c.translationPrompt = prompt
+		return nil
+	}
+}
+
+// WithLanguage translates generated messages to the language code, e.g. "fr" or "zh-cn"
+func WithLanguage(lang string) Option {
+	return func(c *Client) error {
+		c.lang = lang
+		return nil
+	}
+}
+
+// WithVCS sets the VCS reading the staged changes in GenerateForRepo, Git() by default
+func WithVCS(v VCS) Option {
+	return func(c *Client) error {
+		c.vcs = v
+		return nil
+	}
+}
+
+// WithHTTPClient sends requests with httpClient instead of a client built from the
+// proxy and timeout of the provider config
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// New creates a client. The provider comes from WithClientConfig, or from the config
+// file loaded with WithConfigFile; one of them is required.
+func New(opts ...Option) (*Client, error) {
+	c := &Client{}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.withConfig {
+		cfgManager, err := config.Open(c.configFile, c.repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		c.cfgManager = cfgManager
+	} else if c.repoPath != "" {
+		return nil, fmt.Errorf("WithRepo requires a config file, use WithConfigFile")
+	}
+
+	if c.config == nil {
+		if c.cfgManager == nil {
+			return nil, fmt.Errorf("no provider configured, use WithClientConfig or WithConfigFile")
+		}
+		provider := c.provider
+		if provider == "" {
+			value, _ := c.cfgManager.Get("provider")
+			provider, _ = value.(string)
+			if provider == "" {
+				return nil, fmt.Errorf("provider not set")
+			}
+		}
+		clientConfig, err := c.cfgManager.GetProviderClientConfig(provider)
+		if err != nil {
+			return nil, err
+		}
+		c.config = clientConfig
+	}
+
+	var profile *config.PromptProfile
+	if c.cfgManager != nil {
+		name := c.profile
+		if name == "" {
+			value, _ := c.cfgManager.Get("prompt.profile")
+			name, _ = value.(string)
+		}
+		if name != "" {
+			var err error
+			if profile, err = c.cfgManager.GetProfile(name); err != nil {
+				return nil, err
+			}
+			profile.Apply(c.config)
+		}
+	} else if c.profile != "" {
+		return nil, fmt.Errorf("WithProfile requires a config file, use WithConfigFile")
+	}
+
+	c.fillDefaults(profile)
+	c.client = client.New(c.config)
+	if c.httpClient != nil {
+		c.client.SetHTTPClient(c.httpClient)
+	}
+	if c.notify != nil {
+		c.client.SetNotify(c.notify)
+	}
+	return c, nil
+}
+
+// fillDefaults sets the settings that were not given as options, from profile and the
+// config file if one was loaded
+func (c *Client) fillDefaults(profile *config.PromptProfile) {
+	if c.cfgManager != nil {
+		if c.prompt == "" {
+			c.prompt = c.cfgManager.GetPrompt(profile, c.rich)
+		}
+		if c.translationPrompt == "" {
+			c.translationPrompt = c.cfgManager.GetTranslationPrompt(profile)
+		}
+		if c.lang == "" {
+			value, _ := c.cfgManager.Get("output.lang")
+			c.lang, _ = value.(string)
+		}
+		value, _ := c.cfgManager.Get("output.rich_template")
+		c.richTemplate, _ = value.(string)
+		c.commitTypes = c.cfgManager.GetCommitTypes()
+		c.outputStyle = c.cfgManager.GetOutputStyle()
+		if c.vcs == nil {
+			c.vcs = Git(git.IgnorePatterns(c.cfgManager)...)
+		}
+	}
+
+	if c.prompt == "" {
+		c.prompt = defaults.PromptDefaults["brief_commit_message"]
+		if c.rich {
+			c.prompt = defaults.PromptDefaults["rich_commit_message"]
+		}
+	}
+	if c.translationPrompt == "" {
+		c.translationPrompt = defaults.PromptDefaults["translation"]
+	}
+	if c.richTemplate == "" {
+		c.richTemplate = "<title>:<summary>\n\n<detail>"
+	}
+	if len(c.commitTypes) == 0 {
+		c.commitTypes = committype.Defaults
+	}
+	if c.outputStyle.Style == "" {
+		c.outputStyle = config.OutputStyleConfig{Style: config.OutputConventional, Emoji: config.EmojiCode}
+	}
+	if c.vcs == nil {
+		c.vcs = Git()
+	}
+}
+
+// languageName returns the name of the language code lang, e.g. "French" for "fr"
+func languageName(lang string) string {
+	if name, ok := config.OutputLanguageMap[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// Complete sends prompt to the provider and returns its answer with the token usage
+func (c *Client) Complete(ctx context.Context, prompt string, history []Message) (*Response, error) {
+	return c.client.Chat(ctx, prompt, history)
+}
+
+// GenerateCommitMessage generates a commit message for diff, translated to the
+// configured language if it is not English. Like the commit command, the message is
+// validated against the commit types, then written in the output style; validation
+// problems are passed to the WithNotify function.
+func (c *Client) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
+	return c.generate(ctx, diff, nil)
+}
+
+// GenerateForRepo generates a commit message for the staged changes of the repository
+// at repoPath. With a config file and git, the scopes of the staged files are enforced.
+func (c *Client) GenerateForRepo(ctx context.Context, repoPath string) (string, error) {
+	diff, err := c.vcs.StagedDiff(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("no staged changes found")
+	}
+
+	var scopes []string
+	if v, ok := c.vcs.(*vcs); ok && c.cfgManager != nil {
+		if gitVCS, ok := v.differ.(*git.GitVCS); ok {
+			if scopes, _, err = commitmsg.StagedScopes(c.cfgManager, gitVCS, repoPath); err != nil {
+				return "", err
+			}
+		}
+	}
+	return c.generate(ctx, diff, scopes)
+}
+
+// generate generates a commit message for diff and finishes it with scopes
+func (c *Client) generate(ctx context.Context, diff string, scopes []string) (string, error) {
+	if strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("diff is empty")
+	}
+	lang := c.lang
+	if lang == "" {
+		lang = "en"
+	}
+	commitPrompt, err := prompt.Render(c.prompt, prompt.Commit{
+		Diff:         diff,
+		Lang:         languageName(lang),
+		RichTemplate: c.richTemplate,
+		Types:        c.commitTypes,
+		OutputStyle:  c.outputStyle.Style,
+		Scopes:       scopes,
+	}.Vars())
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.Complete(ctx, commitPrompt, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	}
+
+	finish := commitmsg.Options{
+		Style:  c.outputStyle,
+		Types:  c.commitTypes,
+		Scopes: scopes,
+		Warn: func(err error) {
+			if c.notify != nil {
+				c.notify(err.Error())
+			}
+		},
+	}
+	if lang != "en" {
+		finish.Translate = func(message string) (string, error) {
+			return c.Translate(ctx, message, lang)
+		}
+	}
+	return commitmsg.Finish(resp.Content, finish)
+}
+
+// Translate translates message to the language code, e.g. "fr" or "zh-cn"
+func (c *Client) Translate(ctx context.Context, message, lang string) (string, error) {
+	name := languageName(lang)
+	translatePrompt, err := prompt.Render(c.translationPrompt, prompt.Vars{
+		"placeholder": message,
+		"message":     message,
+		"lang":        name,
+		"output.lang": name,
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.Complete(ctx, translatePrompt, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to translate commit message: %w", err)
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
@@ -0,0 +1,296 @@
+package gptcomet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProvider returns an OpenAI compatible server echoing the first line of the prompt
+func newTestProvider(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		prompt := req.Messages[len(req.Messages)-1].Content
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": " " + strings.SplitN(prompt, "\n", 2)[0] + " "}}},
+			"usage":   map[string]int{"prompt_tokens": 5, "completion_tokens": 3, "total_tokens": 8},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestNewRequiresProvider(t *testing.T) {
+	_, err := New()
+	assert.ErrorContains(t, err, "no provider configured")
+
+	_, err = New(WithClientConfig(nil))
+	assert.Error(t, err)
+}
+
+func TestGenerateCommitMessage(t *testing.T) {
+	server := newTestProvider(t)
+	var notices []string
+	c, err := New(
+		WithClientConfig(&ClientConfig{Provider: "openai", APIBase: server.URL, APIKey: "test", Model: "gpt-4o"}),
+		WithPrompt("diff: {{ placeholder }}"),
+		WithNotify(func(message string) {
+			notices = append(notices, message)
+		}),
+	)
+	require.NoError(t, err)
+
+	var message string
+	out := captureStdout(t, func() {
+		message, err = c.GenerateCommitMessage(context.Background(), "+fix")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "diff: +fix", message)
+	assert.Empty(t, out)
+	// The message is validated like with the commit command
+	require.Len(t, notices, 1)
+	assert.Contains(t, notices[0], `unknown commit type "diff"`)
+
+	_, err = c.GenerateCommitMessage(context.Background(), " ")
+	assert.ErrorContains(t, err, "diff is empty")
+
+	resp, err := c.Complete(context.Background(), "hello", nil)
+	require.NoError(t, err)
+	assert.Equal(t, &Usage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8}, resp.Usage)
+}
+
+func TestTranslate(t *testing.T) {
+	server := newTestProvider(t)
+	c, err := New(
+		WithClientConfig(&ClientConfig{Provider: "openai", APIBase: server.URL, APIKey: "test"}),
+		WithPrompt("{{ placeholder }}"),
+		WithTranslationPrompt("to {{ output.lang }}: {{ placeholder }}"),
+		WithLanguage("fr"),
+	)
+	require.NoError(t, err)
+
+	message, err := c.GenerateCommitMessage(context.Background(), "feat: add api")
+	require.NoError(t, err)
+	assert.Equal(t, "to French: feat: add api", message)
+
+	message, err = c.Translate(context.Background(), "fix: typo", "klingon")
+	require.NoError(t, err)
+	assert.Equal(t, "to klingon: fix: typo", message)
+}
+
+func TestWithConfigFile(t *testing.T) {
+	server := newTestProvider(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`provider: openai
+openai:
+  api_key: test
+  api_base: `+server.URL+`
+  model: gpt-4o
+ollama:
+  api_key: unused
+  api_base: http://127.0.0.1:1
+  model: llama3
+output:
+  lang: en
+prompt:
+  brief_commit_message: "configured {{ placeholder }}"
+`), 0644))
+
+	c, err := New(WithConfigFile(path))
+	require.NoError(t, err)
+	var message string
+	out := captureStdout(t, func() {
+		message, err = c.GenerateCommitMessage(context.Background(), "diff")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "configured diff", message)
+	assert.Empty(t, out)
+
+	c, err = New(WithConfigFile(path), WithProvider("ollama"))
+	require.NoError(t, err)
+	assert.Equal(t, "llama3", c.config.Model)
+
+	_, err = New(WithConfigFile(path), WithProvider("missing"))
+	assert.Error(t, err)
+
+	// The config file is never created
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	_, err = New(WithConfigFile(missing))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.NoFileExists(t, missing)
+
+	// The repository config is only read with WithRepo
+	repo := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".gptcomet.yaml"), []byte(`prompt:
+  brief_commit_message: "repo {{ placeholder }}"
+`), 0644))
+	c, err = New(WithConfigFile(path))
+	require.NoError(t, err)
+	message, err = c.GenerateCommitMessage(context.Background(), "diff")
+	require.NoError(t, err)
+	assert.Equal(t, "configured diff", message)
+
+	c, err = New(WithConfigFile(path), WithRepo(repo))
+	require.NoError(t, err)
+	message, err = c.GenerateCommitMessage(context.Background(), "diff")
+	require.NoError(t, err)
+	assert.Equal(t, "repo diff", message)
+
+	_, err = New(WithClientConfig(&ClientConfig{Provider: "openai", APIKey: "test"}), WithRepo(repo))
+	assert.Error(t, err)
+}
+
+func TestWithProfile(t *testing.T) {
+	server := newTestProvider(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`provider: openai
+openai:
+  api_key: test
+  api_base: `+server.URL+`
+  model: gpt-4o
+output:
+  lang: en
+prompt:
+  profile: terse
+  profiles:
+    terse:
+      brief_commit_message: "terse {{ diff }}"
+    kernel:
+      brief_commit_message: "kernel {{ diff }}"
+      model: gpt-4o-mini
+`), 0644))
+
+	c, err := New(WithConfigFile(path))
+	require.NoError(t, err)
+	message, err := c.GenerateCommitMessage(context.Background(), "diff")
+	require.NoError(t, err)
+	assert.Equal(t, "terse diff", message)
+
+	c, err = New(WithConfigFile(path), WithProfile("kernel"))
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o-mini", c.config.Model)
+	message, err = c.GenerateCommitMessage(context.Background(), "diff")
+	require.NoError(t, err)
+	assert.Equal(t, "kernel diff", message)
+
+	_, err = New(WithConfigFile(path), WithProfile("missing"))
+	assert.Error(t, err)
+	_, err = New(WithClientConfig(&ClientConfig{Provider: "openai", APIKey: "test"}), WithProfile("kernel"))
+	assert.Error(t, err)
+}
+
+type fakeVCS struct {
+	diff string
+	err  error
+}
+
+func (f fakeVCS) StagedDiff(repoPath string) (string, error) {
+	return f.diff, f.err
+}
+
+func TestGenerateForRepo(t *testing.T) {
+	server := newTestProvider(t)
+	config := &ClientConfig{Provider: "openai", APIBase: server.URL, APIKey: "test"}
+
+	c, err := New(WithClientConfig(config), WithPrompt("{{ placeholder }}"), WithVCS(fakeVCS{diff: "staged change"}))
+	require.NoError(t, err)
+	message, err := c.GenerateForRepo(context.Background(), ".")
+	require.NoError(t, err)
+	assert.Equal(t, "staged change", message)
+
+	c, err = New(WithClientConfig(config), WithVCS(fakeVCS{}))
+	require.NoError(t, err)
+	_, err = c.GenerateForRepo(context.Background(), ".")
+	assert.ErrorContains(t, err, "no staged changes")
+
+	c, err = New(WithClientConfig(config), WithVCS(fakeVCS{err: errors.New("not a repository")}))
+	require.NoError(t, err)
+	_, err = c.GenerateForRepo(context.Background(), ".")
+	assert.ErrorContains(t, err, "not a repository")
+}
+
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := newTestProvider(t)
+	transport := &countingTransport{}
+	httpClient := &http.Client{Transport: transport}
+
+	c, err := New(
+		WithClientConfig(&ClientConfig{Provider: "openai", APIBase: server.URL, APIKey: "test"}),
+		WithHTTPClient(httpClient),
+	)
+	require.NoError(t, err)
+	_, err = c.GenerateCommitMessage(context.Background(), "diff")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.requests))
+	// the given client is not modified
+	assert.Same(t, transport, httpClient.Transport)
+}
+
+// upperLLM is a custom provider answering with the upper cased prompt
+type upperLLM struct {
+	*BaseLLM
+}
+
+func (u *upperLLM) Name() string {
+	return "upper"
+}
+
+func (u *upperLLM) MakeRequest(ctx context.Context, client *http.Client, message string, history []Message) (string, error) {
+	return strings.ToUpper(message), nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	require.NoError(t, RegisterProvider("upper", func(config *ClientConfig) LLM {
+		return &upperLLM{BaseLLM: NewBaseLLM(config)}
+	}))
+	assert.Error(t, RegisterProvider("", nil))
+
+	c, err := New(WithClientConfig(&ClientConfig{Provider: "upper"}), WithPrompt("fix: {{ placeholder }}"))
+	require.NoError(t, err)
+	message, err := c.GenerateCommitMessage(context.Background(), "typo")
+	require.NoError(t, err)
+	assert.Equal(t, "FIX: TYPO", message)
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 const (
 	DefaultAPIBase          = "https://api.openai.com/v1"
 	DefaultModel            = "gpt-4o"
@@ -31,6 +33,12 @@ type CompletionRequest struct {
 type CompletionResponse struct {
 	Content string                 `json:"content"`
 	Raw     map[string]interface{} `json:"raw"`
+	// Usage is the token usage reported by the provider, nil if it was not reported
+	Usage *Usage `json:"usage,omitempty"`
+	// FirstByte is the time until the first chunk of a streamed answer arrived, the time
+	// to first token. Otherwise it is the time until the response headers arrived, close
+	// to the total latency.
+	FirstByte time.Duration `json:"first_byte,omitempty"`
 }
 
 // Choice represents a completion choice
@@ -73,4 +81,28 @@ type ClientConfig struct {
 	Provider          string            `json:"provider"`
 	ProjectID         string            `json:"project_id,omitempty"` // Vertex AI project ID
 	Location          string            `json:"location,omitempty"`   // Vertex AI location
+	Limits            Limits            `json:"limits,omitempty"`
+	Fallback          *ClientConfig     `json:"fallback,omitempty"` // Provider used when a limit would be exceeded
+}
+
+// Limits are the token and spending guard rails checked before a request is sent.
+// Zero values mean no limit.
+type Limits struct {
+	MaxPromptTokens    int     `json:"max_prompt_tokens,omitempty"`
+	DailyTokenBudget   int     `json:"daily_token_budget,omitempty"`
+	MonthlyTokenBudget int     `json:"monthly_token_budget,omitempty"`
+	DailyCostBudget    float64 `json:"daily_cost_budget,omitempty"`   // USD
+	MonthlyCostBudget  float64 `json:"monthly_cost_budget,omitempty"` // USD
+	PromptPrice        float64 `json:"prompt_price,omitempty"`        // USD per million prompt tokens
+	CompletionPrice    float64 `json:"completion_price,omitempty"`    // USD per million completion tokens
+	// Confirm asks before exceeding a limit instead of refusing
+	Confirm bool `json:"confirm,omitempty"`
+	// Ledger is the usage ledger the budgets are checked against and requests are
+	// recorded to, required by the budgets
+	Ledger string `json:"ledger,omitempty"`
+}
+
+// HasBudget reports whether any daily or monthly budget is set
+func (l Limits) HasBudget() bool {
+	return l.DailyTokenBudget > 0 || l.MonthlyTokenBudget > 0 || l.DailyCostBudget > 0 || l.MonthlyCostBudget > 0
 }